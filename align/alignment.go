@@ -1,73 +1,91 @@
-package main
+package align
 
-import (
-	"fmt"
-	"os"
-	"strings"
-)
+import "fmt"
 
 //Multiple Sequence Alignment -- Star heuristic
-//Input: gene sequences or FASTA files from different species
-//Output: a distance matrix of these sequences derived from the multiple sequence alignment
+//Input: gene sequences
+//Output: the multiple sequence alignment of the genes, from which the distance package builds a distance matrix
+
+//Options are the choices of a multiple alignment made with Align. The zero value of a string option is its default.
+type Options struct {
+	Mode      string              //pairwise alignment mode: global, local or semiglobal
+	Method    string              //multiple alignment method: star or progressive
+	Guide     string              //guide tree of the progressive alignment and of refinement: nj or upgma
+	Scores    *SubstitutionMatrix //scores of matches and mismatches, MatchMatrix(1, -1) if nil
+	GapOpen   int                 //score for opening a gap
+	GapExtend int                 //score for each position of a gap
+	Refine    int                 //maximum number of refinement rounds
+}
 
-func main() {
+//DefaultOptions returns the options the phylo command aligns with when no flags are given.
+func DefaultOptions() Options {
+	return Options{Mode: Global, Method: Star, Guide: GuideNJ, Scores: MatchMatrix(1, -1), GapExtend: -2}
+}
 
-	//input files are multiple FASTA files
-	genes := []string{}
-	speciesName := []string{}
-	//loop through each input FASTA files, store the content to a string, and append it to genes
-	for _, filename := range os.Args[1:] {
-		file, err := os.Open(filename)
+//Align takes genes and alignment options, checks the options and returns the multiple alignment of the genes
+//in input order, with the sum-of-pairs score after each refinement round.
+func Align(genes []string, opts Options) ([]string, []int, error) {
+	if opts.Mode == "" {
+		opts.Mode = Global
+	}
+	if opts.Method == "" {
+		opts.Method = Star
+	}
+	if opts.Guide == "" {
+		opts.Guide = GuideNJ
+	}
+	if opts.Scores == nil {
+		opts.Scores = MatchMatrix(1, -1)
+	}
+	if opts.Mode != Global && opts.Mode != Local && opts.Mode != SemiGlobal {
+		return nil, nil, fmt.Errorf("unknown alignment mode %q", opts.Mode)
+	}
+	if (opts.Method != Star && opts.Method != Progressive) || (opts.Guide != GuideNJ && opts.Guide != GuideUPGMA) {
+		return nil, nil, fmt.Errorf("unknown multiple alignment method %q or guide tree %q", opts.Method, opts.Guide)
+	}
+	if len(genes) == 0 {
+		return nil, nil, fmt.Errorf("no genes to align")
+	}
+	var msa []string
+	if opts.Method == Progressive {
+		var err error
+		msa, err = ProgressiveAlignment(genes, opts.Guide, opts.Mode, opts.Scores, opts.GapOpen, opts.GapExtend)
 		if err != nil {
-			fmt.Println("Error: couldn't open the file")
-			os.Exit(1)
+			return nil, nil, err
 		}
-		defer file.Close()
-		species := filename[:strings.IndexByte(filename, '.')]
-		speciesName = append(speciesName, species)
-		gene := ReadGeneFromFile(filename)
-		genes = append(genes, gene)
-	}
-	fmt.Println("We have read genes from file.")
-
-	/*
-		//test case
-		gene1 := "ATTCGGATT"
-		gene2 := "ATCCGGATT"
-		gene3 := "ATGGAATTTT"
-		gene4 := "ATGTTGTT"
-		gene5 := "AGTCAGG"
-		genes := []string{gene1, gene2, gene3, gene4, gene5}
-		speciesName := []string{"gene1", "gene2", "gene3", "gene4", "gene5"}
-		fmt.Println(AlignGenes(gene1, gene2, 1, -1, -2))
-		fmt.Println(AlignGenes(gene1, gene3, 1, -1, -2))
-		fmt.Println(AlignGenes(gene1, gene4, 1, -1, -2))
-		fmt.Println(AlignGenes(gene1, gene5, 1, -1, -2))
-		fmt.Println("*******************************************")
-	*/
-
-	msa := MultipleAlignment(genes, 1, -1, -2)
-	fmt.Println("Multiple Sequence Alignment have succeeded!")
-	// for i := range msa {
-	// 	fmt.Println(msa[i])
-	// 	fmt.Println(len(msa[i]))
-	// }
-	matrix := DistanceMatrix(msa)
-	fmt.Println(matrix)
-	WriteMatrixToFile(speciesName, matrix, "distanceMatrix.txt")
-	fmt.Println("We have finishing writing the distance matrix to file!")
+	} else {
+		msa = MultipleAlignment(genes, opts.Mode, opts.Scores, opts.GapOpen, opts.GapExtend)
+	}
+	if opts.Refine > 0 {
+		return RefineAlignment(msa, opts.Guide, opts.Scores, opts.GapOpen, opts.GapExtend, opts.Refine)
+	}
+	return msa, []int{}, nil
 }
 
 //Part 1: Pairwise Alignment
 
-//AlignGenes will take two genes as input with factors for Needleman-Wunsch algorithm and returns the alignment of the two genes.
-func AlignGenes(gene1, gene2 string, reward, penalty, gap int) []string {
-	return Traceback(gene1, gene2, reward, penalty, gap, NWMatrix(gene1, gene2, reward, penalty, gap))
+//AlignGenes will take two genes as input with a substitution matrix and gap scores for Needleman-Wunsch algorithm and returns the alignment of the two genes.
+//A gap of length k scores gapOpen + k*gapExtend. Affine gaps (gapOpen != 0) are aligned with Gotoh's algorithm.
+//Genes whose matrix would have more than HirschbergThreshold cells are aligned in linear memory, with Hirschberg's
+//algorithm for linear gaps and Myers and Miller's for affine gaps. These return an alignment with the same optimal
+//score, though not always the same alignment as Traceback or Gotoh.
+func AlignGenes(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int) []string {
+	long := (len(gene1)+1)*(len(gene2)+1) > HirschbergThreshold
+	if gapOpen != 0 {
+		if long {
+			return MyersMiller(gene1, gene2, scores, gapOpen, gapExtend)
+		}
+		return Gotoh(gene1, gene2, scores, gapOpen, gapExtend)
+	}
+	if long {
+		return Hirschberg(gene1, gene2, scores, gapExtend)
+	}
+	return Traceback(gene1, gene2, scores, gapExtend, NWMatrix(gene1, gene2, scores, gapExtend))
 }
 
 //NWMatrix uses Needleman-Wunsch algorithm to build a matrix. Each cell in the matrix represents a partial alignment.
 //The matrix can then be used to traceback and find the alignment of the two gene inputs.
-func NWMatrix(gene1, gene2 string, reward, penalty, gap int) [][]int {
+func NWMatrix(gene1, gene2 string, scores *SubstitutionMatrix, gap int) [][]int {
 	//initialize the matrix
 	matrix := make([][]int, len(gene1)+1)
 	for row := range matrix {
@@ -84,7 +102,7 @@ func NWMatrix(gene1, gene2 string, reward, penalty, gap int) [][]int {
 	//Loop through and fill out the rest cells in the matrix
 	for r := 1; r < len(matrix); r++ {
 		for c := 1; c < len(matrix[r]); c++ {
-			diagonal := matrix[r-1][c-1] + Match(gene1[r-1:r], gene2[c-1:c], reward, penalty)
+			diagonal := matrix[r-1][c-1] + scores.Score(gene1[r-1], gene2[c-1])
 			left := matrix[r][c-1] + gap
 			up := matrix[r-1][c] + gap
 			//Each cell is determined by the maximum among cell on its left, cell on its up, and cell on its diagonal
@@ -94,15 +112,6 @@ func NWMatrix(gene1, gene2 string, reward, penalty, gap int) [][]int {
 	return matrix
 }
 
-//Match takes two nucleotides as input and checks if they are the same.
-func Match(n1, n2 string, reward, penalty int) int {
-	if n1 == n2 {
-		return reward
-	} else {
-		return penalty
-	}
-}
-
 //MaxBtwThree takes three integers as inputs and return the maximum among them.
 func MaxBtwThree(diagonal, left, up int) int {
 	return MaxBtwTwo(MaxBtwTwo(diagonal, left), up)
@@ -119,87 +128,93 @@ func MaxBtwTwo(a, b int) int {
 
 //Traceback takes two genes sequences, the matrix generated by Needleman-Wunsch algorithm as inputs
 //and returns a slice containing the sequences of the two genes after alignment
-func Traceback(gene1, gene2 string, reward, penalty, gap int, matrix [][]int) []string {
-	align1 := "" //align1 will record gene1 after alignment
-	align2 := "" //align2 will record gene2 after alignment
+func Traceback(gene1, gene2 string, scores *SubstitutionMatrix, gap int, matrix [][]int) []string {
+	//align1 and align2 record gene1 and gene2 after alignment, from the end to the start
+	align1 := make([]byte, 0, len(gene1)+len(gene2))
+	align2 := make([]byte, 0, len(gene1)+len(gene2))
 
 	r := len(gene1)
 	c := len(gene2)
 
 	for r*c != 0 {
 		//If the matrix[r][c] is from its diagonal cell
-		if matrix[r][c] == matrix[r-1][c-1]+Match(gene1[r-1:r], gene2[c-1:c], reward, penalty) {
-			align1 += gene1[r-1 : r]
-			align2 += gene2[c-1 : c]
+		if matrix[r][c] == matrix[r-1][c-1]+scores.Score(gene1[r-1], gene2[c-1]) {
+			align1 = append(align1, gene1[r-1])
+			align2 = append(align2, gene2[c-1])
 			r--
 			c--
 			//If the matrix[r][c] is from its left cell
 		} else if matrix[r][c] == matrix[r][c-1]+gap {
-			align1 += "-"
-			align2 += gene2[c-1 : c]
+			align1 = append(align1, '-')
+			align2 = append(align2, gene2[c-1])
 			c--
 			//If the matrix[r][c] is from its up cell
 		} else {
-			align1 += gene1[r-1 : r]
-			align2 += "-"
+			align1 = append(align1, gene1[r-1])
+			align2 = append(align2, '-')
 			r--
 		}
 	}
 
 	//if r (gene1) still has nucleotides that have not covered by traceback
 	for r != 0 {
-		align1 += gene1[r-1 : r]
-		align2 += "-" //add gap to the alignment of gene2
+		align1 = append(align1, gene1[r-1])
+		align2 = append(align2, '-') //add gap to the alignment of gene2
 		r--
 	}
 
 	//if c (gene2) still has nucleotides that have not covered by traceback
 	for c != 0 {
-		align1 += "-" //add gap to the alignment of gene1
-		align2 += gene2[c-1 : c]
+		align1 = append(align1, '-') //add gap to the alignment of gene1
+		align2 = append(align2, gene2[c-1])
 		c--
 	}
 
 	//The actual sequence should be the reverse of align1
-	align1 = ReverseSeq(align1)
-	align2 = ReverseSeq(align2)
-	alignment := []string{align1, align2}
+	alignment := []string{ReverseSeq(string(align1)), ReverseSeq(string(align2))}
 	return alignment
 }
 
 //ReverseSeq takes a gene sequence as input and returns the reverse of it.
 func ReverseSeq(gene string) string {
-	revGene := ""
-	for i := len(gene) - 1; i >= 0; i-- {
-		revGene += gene[i : i+1]
+	revGene := make([]byte, len(gene))
+	for i := 0; i < len(gene); i++ {
+		revGene[len(gene)-1-i] = gene[i]
 	}
-	return revGene
+	return string(revGene)
 }
 
 //Part 2: Multiple Sequence Alignment -- star alignment method
 
-//MultipleAlignment takes genes from multiple species, reward, penalty, and gap scores for pairwise alignment as inputs
-//It returns a slice of strings which contain the genes after alignment by using star alignment method
-func MultipleAlignment(genes []string, reward, penalty, gap int) []string {
+//MultipleAlignment takes genes from multiple species, the pairwise alignment mode, a substitution matrix, and gap open and gap extension scores as inputs
+//It returns a slice of strings which contain the genes after alignment by using star alignment method,
+//in the same order as the input genes.
+func MultipleAlignment(genes []string, mode string, scores *SubstitutionMatrix, gapOpen, gapExtend int) []string {
 	mAlign := []string{}
+	if len(genes) == 1 {
+		return []string{genes[0]}
+	}
 
 	//Get the score of each pairwise alignment for each pair of genes in genes list.
-	scorematrix := ScoreMatrix(genes, reward, penalty, gap)
+	scorematrix := ScoreMatrix(genes, mode, scores, gapOpen, gapExtend)
 
 	//Get the row index of the row with maximum row score sum and this row is the centerGene.
 	index := MaxRow(scorematrix)
 	centerGene := genes[index]
 
-	//Delete the centerGene from the gene list.
-	genes = append(genes[:index], genes[index+1:]...)
+	//Delete the centerGene from a copy of the gene list.
+	others := make([]string, 0, len(genes)-1)
+	others = append(others, genes[:index]...)
+	others = append(others, genes[index+1:]...)
+	genes = others
 
 	for i := range genes {
 		if len(mAlign) == 0 {
 			//Add the pairwise alignment of the centerGene and the first gene in the genes list to mAlign
-			pAlign := AlignGenes(centerGene, genes[i], reward, penalty, gap)
+			pAlign := AlignPadded(centerGene, genes[i], mode, scores, gapOpen, gapExtend)
 			mAlign = pAlign
 		} else {
-			pAlign := AlignGenes(centerGene, genes[i], reward, penalty, gap)
+			pAlign := AlignPadded(centerGene, genes[i], mode, scores, gapOpen, gapExtend)
 			mcenter := mAlign[0]
 			pcenter := pAlign[0]
 			mcGap := CountGap(mcenter) //location of gaps for the centerGene in mAlign
@@ -223,7 +238,12 @@ func MultipleAlignment(genes []string, reward, penalty, gap int) []string {
 			mAlign = append(mAlign, pAlign[1])
 		}
 	}
-	return mAlign
+	//mAlign starts with the centerGene, put it back at its place in the input order
+	ordered := make([]string, 0, len(mAlign))
+	ordered = append(ordered, mAlign[1:index+1]...)
+	ordered = append(ordered, mAlign[0])
+	ordered = append(ordered, mAlign[index+1:]...)
+	return ordered
 }
 
 //CountGap takes a gene as input and record the location of gaps on the gene in a slice.
@@ -303,8 +323,8 @@ func InsertGap(genes []string, i int) []string {
 	return seqs
 }
 
-//ScoreMatrix takes a slice of genes as input and returns a score matrix for pairwise alignments between each two of them.
-func ScoreMatrix(genes []string, reward, penalty, gap int) [][]int {
+//ScoreMatrix takes a slice of genes as input and returns a score matrix for pairwise alignments in the given mode between each two of them.
+func ScoreMatrix(genes []string, mode string, scores *SubstitutionMatrix, gapOpen, gapExtend int) [][]int {
 	dim := len(genes) //dimension of the matrix depends on the number of genes in the slice
 	matrix := make([][]int, dim)
 	for i := range matrix {
@@ -320,10 +340,8 @@ func ScoreMatrix(genes []string, reward, penalty, gap int) [][]int {
 	}
 	for i := 0; i <= dim-2; i++ {
 		for j := i + 1; j <= dim-1; j++ {
-			//Call pairwise alignment.
-			alignment := AlignGenes(genes[i], genes[j], reward, penalty, gap)
-			//Calculate the alignment score.
-			score := AlignmentScore(alignment, reward, penalty, gap)
+			//Call pairwise alignment and take its score.
+			score := AlignPair(genes[i], genes[j], mode, scores, gapOpen, gapExtend).Score
 			matrix[i][j] = score
 			matrix[j][i] = score
 		}
@@ -331,19 +349,35 @@ func ScoreMatrix(genes []string, reward, penalty, gap int) [][]int {
 	return matrix
 }
 
-//AlignmentScore takes a slice of two gene sequences after pairwise alignment and returns an alignment score based on nucleotide match, mismatch and gap.
-func AlignmentScore(genes []string, reward, penalty, gap int) int {
+//AlignmentScore takes a slice of two gene sequences after pairwise alignment and returns an alignment score based on the substitution matrix and gaps.
+//A run of k gaps in the same gene scores gapOpen + k*gapExtend. Columns with a gap in both genes are skipped.
+func AlignmentScore(genes []string, scores *SubstitutionMatrix, gapOpen, gapExtend int) int {
 	score := 0
 	gene1 := genes[0]
 	gene2 := genes[1]
+	inGap1 := false //the previous column has a gap in gene1
+	inGap2 := false //the previous column has a gap in gene2
 	for i := range gene1 {
-		if gene1[i:i+1] == gene2[i:i+1] { //match
-			score += reward
-		} else if gene1[i:i+1] == "-" || gene2[i:i+1] == "-" { //gap
-			score += gap
-		} else { //mismatch
-			score += penalty
+		gap1 := gene1[i:i+1] == "-"
+		gap2 := gene2[i:i+1] == "-"
+		if gap1 && gap2 {
+			continue
 		}
+		if gap1 { //gap in gene1
+			if !inGap1 {
+				score += gapOpen
+			}
+			score += gapExtend
+		} else if gap2 { //gap in gene2
+			if !inGap2 {
+				score += gapOpen
+			}
+			score += gapExtend
+		} else { //match or mismatch
+			score += scores.Score(gene1[i], gene2[i])
+		}
+		inGap1 = gap1
+		inGap2 = gap2
 	}
 	return score
 }
@@ -371,33 +405,3 @@ func MaxRow(scorematrix [][]int) int {
 	}
 	return index
 }
-
-//Part3: Use the multiple sequence alignment to build a distance matrix between each pair of species
-
-//DistanceMatrix takes the MSA result as input and returns a matrix such that each cell corresponding to the number of mismatches between the genes of two species.
-func DistanceMatrix(msa []string) [][]int {
-	numSpecies := len(msa)
-	dmatrix := make([][]int, numSpecies) //(len(genes)-1)*(len(genes)-1) matrix
-	for i := range dmatrix {
-		dmatrix[i] = make([]int, numSpecies)
-	}
-	for i := 0; i <= numSpecies-2; i++ {
-		for j := i + 1; j <= numSpecies-1; j++ {
-			mismatch := Mismatch(msa[i], msa[j])
-			dmatrix[i][j] = mismatch
-			dmatrix[j][i] = mismatch
-		}
-	}
-	return dmatrix
-}
-
-//Mismatch takes two genes as inputs and returns the number of mismatches between them.
-func Mismatch(gene1, gene2 string) int {
-	count := 0
-	for i := range gene1 {
-		if gene1[i:i+1] != gene2[i:i+1] {
-			count++
-		}
-	}
-	return count
-}
@@ -0,0 +1,164 @@
+package align
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//Codon-aware alignment
+//The coding sequence (CDS) of each mRNA is either given by the user or taken as its longest open reading frame.
+//The CDSs are translated with the standard genetic code, the proteins are aligned with a protein matrix, and each
+//amino acid is replaced by its codon and each gap by three gaps, so the nucleotide alignment stays in frame.
+
+//CDS is the coding part gene[Start:End] of a gene, without its stop codon.
+type CDS struct {
+	Start, End int
+}
+
+//GeneticCode maps every codon to its amino acid in the standard genetic code, with '*' for stop codons.
+var GeneticCode = StandardCode()
+
+//StandardCode returns the standard genetic code.
+func StandardCode() map[string]byte {
+	bases := "TCAG"
+	aminoAcids := "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"
+	code := make(map[string]byte, 64)
+	for i := range bases {
+		for j := range bases {
+			for k := range bases {
+				codon := string([]byte{bases[i], bases[j], bases[k]})
+				code[codon] = aminoAcids[16*i+4*j+k]
+			}
+		}
+	}
+	return code
+}
+
+//Translate takes a coding sequence and returns its protein. Codons with ambiguous nucleotides are translated as X,
+//and a last incomplete codon is dropped.
+func Translate(cds string) string {
+	protein := make([]byte, 0, len(cds)/3)
+	for i := 0; i+3 <= len(cds); i += 3 {
+		aa, ok := GeneticCode[strings.Replace(cds[i:i+3], "U", "T", -1)]
+		if !ok {
+			aa = 'X'
+		}
+		protein = append(protein, aa)
+	}
+	return string(protein)
+}
+
+//LongestORF returns the longest open reading frame of a gene on its forward strand: from an ATG to the codon before
+//the next stop codon in the same frame, or to the last whole codon of the gene if there is no stop.
+//If the gene has no ATG, the whole gene in the first frame is returned.
+func LongestORF(gene string) CDS {
+	best := CDS{Start: 0, End: len(gene) - len(gene)%3}
+	found := false
+	for frame := 0; frame < 3; frame++ {
+		start := -1
+		for i := frame; i+3 <= len(gene); i += 3 {
+			aa := GeneticCode[strings.Replace(gene[i:i+3], "U", "T", -1)]
+			if start < 0 && aa == 'M' {
+				start = i
+			}
+			if start >= 0 && aa == '*' {
+				if !found || i-start > best.End-best.Start {
+					best = CDS{Start: start, End: i}
+					found = true
+				}
+				start = -1
+			}
+		}
+		if start >= 0 {
+			end := len(gene) - (len(gene)-start)%3
+			if !found || end-start > best.End-best.Start {
+				best = CDS{Start: start, End: end}
+				found = true
+			}
+		}
+	}
+	return best
+}
+
+//ReadCDSFromFile reads CDS coordinates from a file with one "name<TAB>start<TAB>end" line per gene, where start and
+//end are the 1-based positions of the first and last nucleotide of the CDS, as in a GenBank record.
+func ReadCDSFromFile(fileName string) (map[string]CDS, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cds := make(map[string]CDS)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("wrong format of CDS line %q", line)
+		}
+		start, err1 := strconv.Atoi(strings.TrimSpace(fields[1]))
+		end, err2 := strconv.Atoi(strings.TrimSpace(fields[2]))
+		if err1 != nil || err2 != nil || start < 1 || end < start {
+			return nil, fmt.Errorf("wrong CDS coordinates for %q", fields[0])
+		}
+		cds[fields[0]] = CDS{Start: start - 1, End: end}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cds, nil
+}
+
+//FindCDS returns the CDS of each gene: the coordinates given for its name, or its longest open reading frame.
+//Given coordinates are shortened to whole codons and a final stop codon is left out.
+func FindCDS(speciesName, genes []string, given map[string]CDS) ([]CDS, error) {
+	cds := make([]CDS, len(genes))
+	for i, gene := range genes {
+		c, ok := given[speciesName[i]]
+		if !ok {
+			cds[i] = LongestORF(gene)
+			continue
+		}
+		if c.End > len(gene) {
+			return nil, fmt.Errorf("CDS of %s ends at %d after the end of the gene (%d)", speciesName[i], c.End, len(gene))
+		}
+		c.End -= (c.End - c.Start) % 3
+		if c.End-c.Start >= 3 && Translate(gene[c.End-3:c.End]) == "*" {
+			c.End -= 3
+		}
+		cds[i] = c
+	}
+	return cds, nil
+}
+
+//BackTranslate takes an aligned protein and the CDS it was translated from, and returns the aligned CDS:
+//every amino acid is replaced by its codon and every gap by three gaps.
+func BackTranslate(aligned, cds string) string {
+	codons := make([]byte, 0, 3*len(aligned))
+	k := 0
+	for i := 0; i < len(aligned); i++ {
+		if aligned[i] == '-' {
+			codons = append(codons, '-', '-', '-')
+		} else {
+			codons = append(codons, cds[k:k+3]...)
+			k += 3
+		}
+	}
+	return string(codons)
+}
+
+//CodonAlignment takes the aligned proteins of the genes and their CDSs, and returns the in-frame nucleotide alignment.
+func CodonAlignment(proteinMSA, genes []string, cds []CDS) []string {
+	msa := make([]string, len(genes))
+	for i, gene := range genes {
+		msa[i] = BackTranslate(proteinMSA[i], gene[cds[i].Start:cds[i].End])
+	}
+	return msa
+}
@@ -0,0 +1,128 @@
+package align
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLongestORF(t *testing.T) {
+	for _, tc := range []struct {
+		gene string
+		want CDS
+	}{
+		{"CATGAAATTTTAAG", CDS{1, 10}},      //second frame, ends before TAA
+		{"GCATGCCCGGGTGACC", CDS{2, 11}},    //third frame, ends before TGA
+		{"GGATGAAACC", CDS{2, 8}},           //no stop: to the last whole codon
+		{"ATGAAACCCGG", CDS{0, 9}},          //no stop in the first frame
+		{"ATGTAAGATGCCCGGGTAG", CDS{7, 16}}, //the longer of two ORFs
+		{"AUGUUUUAA", CDS{0, 6}},            //RNA
+		{"CCCGGGT", CDS{0, 6}},              //no ATG: the whole gene in the first frame
+	} {
+		if got := LongestORF(tc.gene); got != tc.want {
+			t.Errorf("LongestORF(%s) = %v, want %v", tc.gene, got, tc.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	for _, tc := range []struct{ cds, want string }{
+		{"ATGTTTTAAGG", "MF*"},
+		{"AUGUUUUAA", "MF*"},
+		{"ATGNNN", "MX"},
+		{"", ""},
+	} {
+		if got := Translate(tc.cds); got != tc.want {
+			t.Errorf("Translate(%s) = %s, want %s", tc.cds, got, tc.want)
+		}
+	}
+}
+
+func TestFindCDS(t *testing.T) {
+	names := []string{"given", "partial", "orf"}
+	genes := []string{"GGATGAAATAGCC", "GGATGAAACCC", "CATGAAATTTTAAG"}
+	given := map[string]CDS{
+		"given":   {2, 11}, //ATGAAATAG, the stop codon is left out
+		"partial": {2, 10}, //ATGAAACC, shortened to whole codons
+	}
+	cds, err := FindCDS(names, genes, given)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CDS{{2, 8}, {2, 8}, {1, 10}}
+	for i := range want {
+		if cds[i] != want[i] {
+			t.Errorf("CDS of %s is %v, want %v", names[i], cds[i], want[i])
+		}
+	}
+
+	given["given"] = CDS{2, 14}
+	if _, err := FindCDS(names, genes, given); err == nil {
+		t.Error("FindCDS accepted a CDS past the end of the gene")
+	}
+}
+
+func TestReadCDSFromFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "cds.txt")
+	if err := os.WriteFile(name, []byte("a\t3\t11\n\nb\t 1\t6 \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cds, err := ReadCDSFromFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cds) != 2 || cds["a"] != (CDS{2, 11}) || cds["b"] != (CDS{0, 6}) {
+		t.Errorf("read %v, want a {2 11} and b {0 6}", cds)
+	}
+
+	for _, bad := range []string{"a\t3\n", "a\tx\t11\n", "a\t0\t11\n", "a\t11\t3\n"} {
+		if err := os.WriteFile(name, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadCDSFromFile(name); err == nil {
+			t.Errorf("ReadCDSFromFile accepted %q", bad)
+		}
+	}
+}
+
+func TestCodonAlignment(t *testing.T) {
+	if got := BackTranslate("M-F", "ATGTTT"); got != "ATG---TTT" {
+		t.Errorf("BackTranslate(M-F, ATGTTT) = %s, want ATG---TTT", got)
+	}
+
+	names := []string{"a", "b", "c"}
+	mrnas := []string{
+		"CCATGGCTTGGAAACATTGGTAAGG",
+		"GATGGCTAAACATTGGTGA",
+		"TTATGTGGAAAGATCATTGGCCTTAGC",
+	}
+	cds, err := FindCDS(names, mrnas, map[string]CDS{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proteins := make([]string, len(mrnas))
+	for i, c := range cds {
+		proteins[i] = Translate(mrnas[i][c.Start:c.End])
+	}
+	proteinMSA, _, err := Align(proteins, Options{Scores: BLOSUM62, GapOpen: -10, GapExtend: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msa := CodonAlignment(proteinMSA, mrnas, cds)
+	for i, row := range msa {
+		if len(row) != 3*len(proteinMSA[0]) {
+			t.Errorf("row %s has length %d, want %d", names[i], len(row), 3*len(proteinMSA[0]))
+		}
+		for k := 0; k+3 <= len(row); k += 3 {
+			codon := row[k : k+3]
+			if strings.Contains(codon, "-") && codon != "---" {
+				t.Errorf("row %s has the codon %s at %d", names[i], codon, k)
+			}
+		}
+		if got, want := RemoveGaps(row), mrnas[i][cds[i].Start:cds[i].End]; got != want {
+			t.Errorf("row %s without gaps is %s, want its CDS %s", names[i], got, want)
+		}
+	}
+}
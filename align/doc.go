@@ -0,0 +1,4 @@
+// Package align aligns genes: global, local and semi-global pairwise alignment with linear or affine gaps, star and
+// progressive multiple alignment with iterative refinement, and codon alignment of coding sequences. It reads FASTA
+// files and writes alignments in the FASTA, PHYLIP, Clustal and Stockholm formats.
+package align
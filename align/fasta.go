@@ -0,0 +1,190 @@
+package align
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//FASTA input
+//A FASTA file holds one or more records, each starting with a ">ID description" header line followed by sequence lines.
+//Sequences are returned in upper case, and every letter is checked against an alphabet.
+
+// Record is one sequence read from a FASTA file.
+type Record struct {
+	ID, Description, Sequence string
+	Source                    string //file the record was read from
+}
+
+// Alphabet is the set of letters allowed in a sequence. All alphabets allow '-' for gaps.
+type Alphabet struct {
+	Name    string
+	Letters string
+}
+
+// DNA, RNA and Protein alphabets, including the IUPAC ambiguity codes.
+var (
+	DNA     = Alphabet{Name: "DNA", Letters: "ACGTRYSWKMBDHVN-"}
+	RNA     = Alphabet{Name: "RNA", Letters: "ACGURYSWKMBDHVN-"}
+	Protein = Alphabet{Name: "protein", Letters: "ACDEFGHIKLMNPQRSTVWYBZJXUO*-"}
+)
+
+// AlphabetByName returns the alphabet named dna, rna or protein.
+func AlphabetByName(name string) (Alphabet, error) {
+	for _, a := range []Alphabet{DNA, RNA, Protein} {
+		if strings.EqualFold(a.Name, name) {
+			return a, nil
+		}
+	}
+	return Alphabet{}, fmt.Errorf("unknown alphabet %s", name)
+}
+
+// Contains returns true if the letter is in the alphabet.
+func (a Alphabet) Contains(letter byte) bool {
+	return strings.IndexByte(a.Letters, letter) >= 0
+}
+
+// ReadFastaFiles reads the records of several FASTA files, in the order of the files.
+func ReadFastaFiles(filenames []string, alphabet Alphabet) ([]Record, error) {
+	records := make([]Record, 0, len(filenames))
+	for _, filename := range filenames {
+		recs, err := ReadFastaFile(filename, alphabet)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, recs...)
+	}
+	return records, nil
+}
+
+// ReadFastaFile reads all records of a FASTA file, which may be gzip compressed.
+func ReadFastaFile(filename string, alphabet Alphabet) ([]Record, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	//gzip files start with the bytes 0x1f 0x8b
+	br := bufio.NewReader(file)
+	var r io.Reader = br
+	magic, _ := br.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	records, err := ReadFasta(r, alphabet)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s: no FASTA records", filename)
+	}
+	for i := range records {
+		records[i].Source = filename
+	}
+	return records, nil
+}
+
+// ReadFasta reads all records from r. Blank lines, ';' comment lines and CRLF line endings are allowed,
+// and sequences are converted to upper case before they are checked against the alphabet.
+func ReadFasta(r io.Reader, alphabet Alphabet) ([]Record, error) {
+	records := make([]Record, 0)
+	reader := bufio.NewReader(r)
+	var seq strings.Builder
+	var current *Record
+	lineNum := 0
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		lineNum++
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.TrimSpace(line) == "" || line[0] == ';':
+			//skip blank and comment lines
+		case line[0] == '>':
+			if current != nil {
+				current.Sequence = seq.String()
+				records = append(records, *current)
+				seq.Reset()
+			}
+			header := strings.TrimSpace(line[1:])
+			if header == "" {
+				return nil, fmt.Errorf("line %d: header without an ID", lineNum)
+			}
+			current = &Record{ID: header}
+			if i := strings.IndexAny(header, " \t"); i >= 0 {
+				current.ID = header[:i]
+				current.Description = strings.TrimSpace(header[i+1:])
+			}
+		default:
+			if current == nil {
+				return nil, fmt.Errorf("line %d: sequence before the first '>' header", lineNum)
+			}
+			for i := 0; i < len(line); i++ {
+				c := line[i]
+				if c == ' ' || c == '\t' {
+					continue
+				}
+				if 'a' <= c && c <= 'z' {
+					c -= 'a' - 'A'
+				}
+				if !alphabet.Contains(c) {
+					return nil, fmt.Errorf("line %d: %q is not a %s letter", lineNum, line[i], alphabet.Name)
+				}
+				seq.WriteByte(c)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if current != nil {
+		current.Sequence = seq.String()
+		records = append(records, *current)
+	}
+	return records, nil
+}
+
+// RecordNames takes the records read from one or more files and returns a name for each of them.
+// A record that is alone in its file is named after the file without its directory and extension,
+// as the species name of single-gene files; otherwise the record ID is used.
+func RecordNames(records []Record) ([]string, error) {
+	perFile := make(map[string]int)
+	for _, rec := range records {
+		perFile[rec.Source]++
+	}
+	names := make([]string, len(records))
+	seen := make(map[string]bool, len(records))
+	for i, rec := range records {
+		names[i] = rec.ID
+		if perFile[rec.Source] == 1 && rec.Source != "" {
+			base := filepath.Base(rec.Source)
+			names[i] = strings.TrimSuffix(base, filepath.Ext(base))
+			if filepath.Ext(base) == ".gz" {
+				names[i] = strings.TrimSuffix(names[i], filepath.Ext(names[i]))
+			}
+		}
+		if seen[names[i]] {
+			return nil, fmt.Errorf("two sequences are named %q", names[i])
+		}
+		seen[names[i]] = true
+	}
+	return names, nil
+}
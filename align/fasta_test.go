@@ -0,0 +1,88 @@
+package align
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFasta(t *testing.T) {
+	// CRLF line endings, blank lines, a comment, lower case letters and a sequence split over two lines
+	input := ">seq1 first gene\r\nacgu\r\n\r\nRYN-\r\n;comment\r\n\r\n>seq2\r\nUUAg\r\n"
+	records, err := ReadFasta(strings.NewReader(input), RNA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{ID: "seq1", Description: "first gene", Sequence: "ACGURYN-"},
+		{ID: "seq2", Sequence: "UUAG"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("%d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if records[i] != want[i] {
+			t.Errorf("record %d is %+v, want %+v", i, records[i], want[i])
+		}
+	}
+
+	if _, err := ReadFasta(strings.NewReader(input), DNA); err == nil {
+		t.Error("the DNA alphabet accepted U")
+	}
+	if _, err := ReadFasta(strings.NewReader("ACGT\n>seq\nACGT\n"), DNA); err == nil {
+		t.Error("ReadFasta accepted a sequence before the first header")
+	}
+	if _, err := ReadFasta(strings.NewReader(">\nACGT\n"), DNA); err == nil {
+		t.Error("ReadFasta accepted a header without an ID")
+	}
+}
+
+func TestReadFastaFileGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(">only\nacgt\nAC\n"))
+	gz.Close()
+	fileName := filepath.Join(t.TempDir(), "cattle.fasta.gz")
+	if err := os.WriteFile(fileName, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadFastaFile(fileName, DNA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Sequence != "ACGTAC" {
+		t.Fatalf("read %+v, want one record ACGTAC", records)
+	}
+	// the only record of a file is named after the file
+	names, err := RecordNames(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names[0] != "cattle" {
+		t.Errorf("record named %q, want cattle", names[0])
+	}
+}
+
+func TestMatrixAlphabet(t *testing.T) {
+	rna, err := ParseSubstitutionMatrix("rna", strings.NewReader("   A  C  G  U\nA  1 -1 -1 -1\nC -1  1 -1 -1\nG -1 -1  1 -1\nU -1 -1 -1  1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		sm   *SubstitutionMatrix
+		want Alphabet
+	}{
+		{MatchMatrix(1, -1), DNA},
+		{DNATransitionTransversion, DNA},
+		{rna, RNA},
+		{BLOSUM62, Protein},
+	}
+	for _, test := range tests {
+		if got := MatrixAlphabet(test.sm); got != test.want {
+			t.Errorf("MatrixAlphabet(%s) = %s, want %s", test.sm.Name, got.Name, test.want.Name)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package align
+
+//Pairwise alignment with affine gap penalties -- Gotoh's algorithm
+//A gap of length k scores gapOpen + k*gapExtend, so one long indel costs less than several short ones.
+//Three matrices are filled in: M for alignments ending with two aligned nucleotides, X for alignments ending
+//with a nucleotide of gene1 against a gap, and Y for alignments ending with a gap against a nucleotide of gene2.
+//With gapOpen = 0 this is the same as Needleman-Wunsch with a linear gap of gapExtend.
+
+//negInf stands for an impossible alignment. It is small enough to never win a maximum and large enough
+//that adding penalties to it does not overflow.
+const negInf = -1 << 40
+
+//GotohMatrices takes two genes and the scores for an affine-gap alignment and returns the M, X and Y matrices.
+func GotohMatrices(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int) ([][]int, [][]int, [][]int) {
+	return GotohMatricesFrom(gene1, gene2, scores, gapOpen, gapExtend, 'M')
+}
+
+//GotohMatricesFrom is GotohMatrices for an alignment that follows state start. If start is 'X', the alignment
+//follows a gap in gene2, so a gap in gene2 at its start extends that gap instead of opening a new one.
+func GotohMatricesFrom(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int, start byte) ([][]int, [][]int, [][]int) {
+	m := NewIntMatrix(len(gene1)+1, len(gene2)+1)
+	x := NewIntMatrix(len(gene1)+1, len(gene2)+1)
+	y := NewIntMatrix(len(gene1)+1, len(gene2)+1)
+
+	//First row and first column can only be reached by gaps.
+	m[0][0] = 0
+	x[0][0] = negInf
+	y[0][0] = negInf
+	open := gapOpen
+	if start == 'X' {
+		x[0][0] = 0
+		open = 0
+	}
+	for r := 1; r <= len(gene1); r++ {
+		m[r][0] = negInf
+		x[r][0] = open + r*gapExtend
+		y[r][0] = negInf
+	}
+	for c := 1; c <= len(gene2); c++ {
+		m[0][c] = negInf
+		x[0][c] = negInf
+		y[0][c] = gapOpen + c*gapExtend
+	}
+
+	for r := 1; r <= len(gene1); r++ {
+		for c := 1; c <= len(gene2); c++ {
+			m[r][c] = MaxBtwThree(m[r-1][c-1], x[r-1][c-1], y[r-1][c-1]) + scores.Score(gene1[r-1], gene2[c-1])
+			//a gap is either opened after a match or a gap in the other gene, or extended
+			x[r][c] = MaxBtwThree(m[r-1][c]+gapOpen+gapExtend, x[r-1][c]+gapExtend, y[r-1][c]+gapOpen+gapExtend)
+			y[r][c] = MaxBtwThree(m[r][c-1]+gapOpen+gapExtend, y[r][c-1]+gapExtend, x[r][c-1]+gapOpen+gapExtend)
+		}
+	}
+	return m, x, y
+}
+
+//NewIntMatrix returns a rows*cols matrix of zeros.
+func NewIntMatrix(rows, cols int) [][]int {
+	matrix := make([][]int, rows)
+	for row := range matrix {
+		matrix[row] = make([]int, cols)
+	}
+	return matrix
+}
+
+//GotohTraceback takes two genes, the scores for an affine-gap alignment and the matrices from GotohMatrices,
+//and returns a slice containing the sequences of the two genes after alignment.
+//Like Traceback, it prefers a match over a gap in gene1 over a gap in gene2 when they score the same.
+func GotohTraceback(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int, m, x, y [][]int) []string {
+	state := GotohBestState(m[len(gene1)][len(gene2)], y[len(gene1)][len(gene2)], x[len(gene1)][len(gene2)])
+	return GotohTracebackFrom(gene1, gene2, scores, gapOpen, gapExtend, m, x, y, state)
+}
+
+//GotohTracebackFrom is GotohTraceback for an alignment that ends in the given state: 'M', 'X' or 'Y'.
+func GotohTracebackFrom(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int, m, x, y [][]int, state byte) []string {
+	align1 := make([]byte, 0, len(gene1)+len(gene2))
+	align2 := make([]byte, 0, len(gene1)+len(gene2))
+
+	r := len(gene1)
+	c := len(gene2)
+	//state is the matrix the current cell comes from: 'M', 'X' or 'Y'
+	for r > 0 || c > 0 {
+		//the first row and column can only be reached by gaps
+		if r == 0 {
+			state = 'Y'
+		} else if c == 0 {
+			state = 'X'
+		}
+		switch state {
+		case 'M':
+			prev := m[r][c] - scores.Score(gene1[r-1], gene2[c-1])
+			state = GotohPrevState(prev, m[r-1][c-1], y[r-1][c-1], x[r-1][c-1])
+			align1 = append(align1, gene1[r-1])
+			align2 = append(align2, gene2[c-1])
+			r--
+			c--
+		case 'X':
+			state = GotohPrevState(x[r][c], m[r-1][c]+gapOpen+gapExtend, y[r-1][c]+gapOpen+gapExtend, x[r-1][c]+gapExtend)
+			align1 = append(align1, gene1[r-1])
+			align2 = append(align2, '-')
+			r--
+		case 'Y':
+			state = GotohPrevState(y[r][c], m[r][c-1]+gapOpen+gapExtend, y[r][c-1]+gapExtend, x[r][c-1]+gapOpen+gapExtend)
+			align1 = append(align1, '-')
+			align2 = append(align2, gene2[c-1])
+			c--
+		}
+	}
+
+	alignment := []string{ReverseSeq(string(align1)), ReverseSeq(string(align2))}
+	return alignment
+}
+
+//GotohBestState returns the matrix with the highest of the three scores, preferring M, then Y, then X.
+func GotohBestState(mScore, yScore, xScore int) byte {
+	if mScore >= yScore && mScore >= xScore {
+		return 'M'
+	}
+	if yScore >= xScore {
+		return 'Y'
+	}
+	return 'X'
+}
+
+//GotohPrevState returns the matrix whose score leads to want, checking M, then Y, then X.
+func GotohPrevState(want, mScore, yScore, xScore int) byte {
+	if want == mScore {
+		return 'M'
+	}
+	if want == yScore {
+		return 'Y'
+	}
+	return 'X'
+}
+
+//Gotoh takes two genes and the scores for an affine-gap alignment and returns the alignment of the two genes.
+func Gotoh(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int) []string {
+	m, x, y := GotohMatrices(gene1, gene2, scores, gapOpen, gapExtend)
+	return GotohTraceback(gene1, gene2, scores, gapOpen, gapExtend, m, x, y)
+}
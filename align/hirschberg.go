@@ -0,0 +1,73 @@
+package align
+
+//Linear-memory pairwise alignment -- Hirschberg's divide and conquer
+//NWMatrix keeps all (n+1)*(m+1) cells, which does not fit in memory for long genes. Hirschberg's algorithm
+//only keeps two rows of the matrix: it scores the top half of gene1 forwards and the bottom half backwards,
+//finds the column where an optimal alignment crosses the middle row, and aligns the two halves on their own.
+//It returns an alignment with the same optimal score as Traceback, not necessarily the same alignment: when
+//several alignments have the optimal score, the two can pick different ones.
+
+//HirschbergThreshold is the number of matrix cells above which AlignGenes switches to Hirschberg's algorithm,
+//or to Myers and Miller's with affine gaps.
+var HirschbergThreshold = 1 << 23
+
+//hirschbergBaseCells is the size below which a subproblem is small enough to align with the full matrix.
+const hirschbergBaseCells = 1 << 12
+
+//Hirschberg takes two genes and the scores for Needleman-Wunsch and returns an optimal alignment of the two genes,
+//using memory linear in the length of the genes.
+func Hirschberg(gene1, gene2 string, scores *SubstitutionMatrix, gap int) []string {
+	align1 := make([]byte, 0, len(gene1)+len(gene2))
+	align2 := make([]byte, 0, len(gene1)+len(gene2))
+	align1, align2 = HirschbergAlign(gene1, gene2, scores, gap, align1, align2)
+	return []string{string(align1), string(align2)}
+}
+
+//HirschbergAlign appends the alignment of gene1 and gene2 to align1 and align2 and returns them.
+func HirschbergAlign(gene1, gene2 string, scores *SubstitutionMatrix, gap int, align1, align2 []byte) ([]byte, []byte) {
+	n := len(gene1)
+	m := len(gene2)
+	//small problems are solved with the full matrix
+	if n <= 1 || m <= 1 || (n+1)*(m+1) <= hirschbergBaseCells {
+		pAlign := Traceback(gene1, gene2, scores, gap, NWMatrix(gene1, gene2, scores, gap))
+		return append(align1, pAlign[0]...), append(align2, pAlign[1]...)
+	}
+
+	mid := n / 2
+	//score of the top half of gene1 against every prefix of gene2
+	forward := NWLastRow(gene1[:mid], gene2, scores, gap)
+	//score of the bottom half of gene1 against every suffix of gene2
+	backward := NWLastRow(ReverseSeq(gene1[mid:]), ReverseSeq(gene2), scores, gap)
+
+	//an optimal alignment crosses the middle row at the column with the best total score
+	split := 0
+	best := forward[0] + backward[m]
+	for j := 1; j <= m; j++ {
+		if forward[j]+backward[m-j] > best {
+			split = j
+			best = forward[j] + backward[m-j]
+		}
+	}
+
+	align1, align2 = HirschbergAlign(gene1[:mid], gene2[:split], scores, gap, align1, align2)
+	return HirschbergAlign(gene1[mid:], gene2[split:], scores, gap, align1, align2)
+}
+
+//NWLastRow takes two genes and the scores for Needleman-Wunsch and returns the last row of NWMatrix,
+//the score of aligning all of gene1 with each prefix of gene2, keeping only two rows in memory.
+func NWLastRow(gene1, gene2 string, scores *SubstitutionMatrix, gap int) []int {
+	prev := make([]int, len(gene2)+1)
+	curr := make([]int, len(gene2)+1)
+	for c := range prev {
+		prev[c] = gap * c
+	}
+	for r := 1; r <= len(gene1); r++ {
+		curr[0] = gap * r
+		for c := 1; c <= len(gene2); c++ {
+			diagonal := prev[c-1] + scores.Score(gene1[r-1], gene2[c-1])
+			curr[c] = MaxBtwThree(diagonal, curr[c-1]+gap, prev[c]+gap)
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
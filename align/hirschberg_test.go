@@ -0,0 +1,107 @@
+package align
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+//randomGenePair returns a random gene of the given length and a copy of it with about 10% of the sites
+//substituted, inserted or deleted, so the pair aligns like two related genes.
+func randomGenePair(length int, rng *rand.Rand) (string, string) {
+	nucs := "ACGT"
+	gene1 := make([]byte, length)
+	for i := range gene1 {
+		gene1[i] = nucs[rng.Intn(4)]
+	}
+	gene2 := make([]byte, 0, length+length/10)
+	for _, nuc := range gene1 {
+		switch rng.Intn(30) {
+		case 0: //substitution
+			gene2 = append(gene2, nucs[rng.Intn(4)])
+		case 1: //insertion
+			gene2 = append(gene2, nuc, nucs[rng.Intn(4)])
+		case 2: //deletion
+		default:
+			gene2 = append(gene2, nuc)
+		}
+	}
+	return string(gene1), string(gene2)
+}
+
+func TestHirschbergScore(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	scores := MatchMatrix(1, -1)
+	for _, length := range []int{10, 100, 500, 2000} {
+		gene1, gene2 := randomGenePair(length, rng)
+		full := Traceback(gene1, gene2, scores, -2, NWMatrix(gene1, gene2, scores, -2))
+		linear := Hirschberg(gene1, gene2, scores, -2)
+		fullScore, linearScore := AlignmentScore(full, scores, 0, -2), AlignmentScore(linear, scores, 0, -2)
+		if fullScore != linearScore {
+			t.Errorf("length %d: Hirschberg score %d, full matrix score %d", length, linearScore, fullScore)
+		}
+	}
+}
+
+//benchmarkAlignment times align on a random gene pair of each length.
+func benchmarkAlignment(b *testing.B, align func(gene1, gene2 string, scores *SubstitutionMatrix)) {
+	rng := rand.New(rand.NewSource(1))
+	scores := MatchMatrix(1, -1)
+	for _, length := range []int{500, 2000, 5000} {
+		gene1, gene2 := randomGenePair(length, rng)
+		b.Run(fmt.Sprint(length), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				align(gene1, gene2, scores)
+			}
+		})
+	}
+}
+
+func BenchmarkNWMatrix(b *testing.B) {
+	benchmarkAlignment(b, func(gene1, gene2 string, scores *SubstitutionMatrix) {
+		Traceback(gene1, gene2, scores, -2, NWMatrix(gene1, gene2, scores, -2))
+	})
+}
+
+func BenchmarkHirschberg(b *testing.B) {
+	benchmarkAlignment(b, func(gene1, gene2 string, scores *SubstitutionMatrix) {
+		Hirschberg(gene1, gene2, scores, -2)
+	})
+}
+
+//checkOptimalAlignment fails the test if msa is not an alignment of gene1 and gene2 with the optimal
+//Needleman-Wunsch score.
+func checkOptimalAlignment(t *testing.T, msa []string, gene1, gene2 string, scores *SubstitutionMatrix, gap int) {
+	t.Helper()
+	if len(msa[0]) != len(msa[1]) {
+		t.Fatalf("aligned genes have lengths %d and %d", len(msa[0]), len(msa[1]))
+	}
+	if RemoveGaps(msa[0]) != gene1 || RemoveGaps(msa[1]) != gene2 {
+		t.Fatalf("%v is not an alignment of %s and %s", msa, gene1, gene2)
+	}
+	optimal := NWMatrix(gene1, gene2, scores, gap)[len(gene1)][len(gene2)]
+	if score := AlignmentScore(msa, scores, 0, gap); score != optimal {
+		t.Errorf("alignment of %s and %s scores %d, optimum %d", gene1, gene2, score, optimal)
+	}
+}
+
+func TestHirschbergIsOptimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	scores := TransitionTransversionMatrix(2, -1, -2)
+	pairs := [][2]string{{"", "ACGT"}, {"A", "ACGT"}, {"ACGT", "A"}, {"AAAA", "AA"}, {"ACACAC", "CACA"}}
+	for _, length := range []int{50, 100, 300, 1000} {
+		gene1, gene2 := randomGenePair(length, rng)
+		pairs = append(pairs, [2]string{gene1, gene2})
+	}
+	for _, pair := range pairs {
+		checkOptimalAlignment(t, Hirschberg(pair[0], pair[1], scores, -3), pair[0], pair[1], scores, -3)
+	}
+
+	//AlignGenes switches to Hirschberg above the threshold
+	defer func(threshold int) { HirschbergThreshold = threshold }(HirschbergThreshold)
+	HirschbergThreshold = 100
+	for _, pair := range pairs {
+		checkOptimalAlignment(t, AlignGenes(pair[0], pair[1], scores, 0, -3), pair[0], pair[1], scores, -3)
+	}
+}
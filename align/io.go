@@ -0,0 +1,169 @@
+package align
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+//Multiple sequence alignment output
+//Every format writes the aligned sequences in the order of the names. Names are written as they are in FASTA;
+//in the other formats whitespace in a name is replaced by '_' because the name ends at the first space.
+
+//AlignmentFormats maps each supported format name to the file extension used for it.
+var AlignmentFormats = map[string]string{
+	"fasta":             ".fasta",
+	"phylip":            ".phy",
+	"phylip-sequential": ".phy",
+	"clustal":           ".aln",
+	"stockholm":         ".sto",
+}
+
+//WriteAlignmentToFile takes a slice of species names, the aligned sequences, a format and an output filename,
+//and writes the multiple sequence alignment to the file in that format.
+func WriteAlignmentToFile(speciesName, msa []string, format, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	switch format {
+	case "fasta":
+		WriteFasta(w, speciesName, msa, 60)
+	case "phylip":
+		WritePhylip(w, speciesName, msa, true)
+	case "phylip-sequential":
+		WritePhylip(w, speciesName, msa, false)
+	case "clustal":
+		WriteClustal(w, speciesName, msa)
+	case "stockholm":
+		WriteStockholm(w, speciesName, msa)
+	default:
+		return fmt.Errorf("unknown alignment format %q", format)
+	}
+	return w.Flush()
+}
+
+//WriteFasta writes the alignment as aligned FASTA with width residues per line.
+func WriteFasta(w io.Writer, speciesName, msa []string, width int) {
+	for i := range msa {
+		fmt.Fprintln(w, ">"+speciesName[i])
+		for start := 0; start < len(msa[i]); start += width {
+			end := start + width
+			if end > len(msa[i]) {
+				end = len(msa[i])
+			}
+			fmt.Fprintln(w, msa[i][start:end])
+		}
+	}
+}
+
+//WritePhylip writes the alignment in relaxed PHYLIP format, where names can be longer than 10 characters and
+//are separated from the sequence by spaces. Interleaved files give 60 columns per block, with the names only in the first block.
+func WritePhylip(w io.Writer, speciesName, msa []string, interleaved bool) {
+	names, width := PaddedNames(speciesName, 10)
+	length := AlignmentLength(msa)
+	fmt.Fprintln(w, len(msa), length)
+	if !interleaved {
+		for i := range msa {
+			fmt.Fprintln(w, names[i]+msa[i])
+		}
+		return
+	}
+	for start := 0; start < length; start += 60 {
+		end := start + 60
+		if end > length {
+			end = length
+		}
+		if start > 0 {
+			fmt.Fprintln(w)
+		}
+		for i := range msa {
+			if start == 0 {
+				fmt.Fprintln(w, names[i]+msa[i][start:end])
+			} else {
+				fmt.Fprintln(w, strings.Repeat(" ", width)+msa[i][start:end])
+			}
+		}
+	}
+}
+
+//WriteClustal writes the alignment in Clustal format in blocks of 60 columns.
+//Under each block, '*' marks the columns where every sequence has the same residue.
+func WriteClustal(w io.Writer, speciesName, msa []string) {
+	names, width := PaddedNames(speciesName, 16)
+	length := AlignmentLength(msa)
+	fmt.Fprintln(w, "CLUSTAL W multiple sequence alignment")
+	fmt.Fprintln(w)
+	for start := 0; start < length; start += 60 {
+		end := start + 60
+		if end > length {
+			end = length
+		}
+		fmt.Fprintln(w)
+		for i := range msa {
+			fmt.Fprintln(w, names[i]+msa[i][start:end])
+		}
+		conservation := make([]byte, 0, end-start)
+		for col := start; col < end; col++ {
+			if ConservedColumn(msa, col) {
+				conservation = append(conservation, '*')
+			} else {
+				conservation = append(conservation, ' ')
+			}
+		}
+		fmt.Fprintln(w, strings.Repeat(" ", width)+string(conservation))
+	}
+}
+
+//WriteStockholm writes the alignment in Stockholm format with each sequence on one line.
+func WriteStockholm(w io.Writer, speciesName, msa []string) {
+	names, _ := PaddedNames(speciesName, 0)
+	fmt.Fprintln(w, "# STOCKHOLM 1.0")
+	fmt.Fprintln(w)
+	for i := range msa {
+		fmt.Fprintln(w, names[i]+msa[i])
+	}
+	fmt.Fprintln(w, "//")
+}
+
+//PaddedNames replaces whitespace in the names with '_' and pads them with spaces to a common width,
+//which is at least minWidth and leaves at least one space after the longest name. It returns the names and the width.
+func PaddedNames(speciesName []string, minWidth int) ([]string, int) {
+	width := minWidth
+	names := make([]string, len(speciesName))
+	for i, name := range speciesName {
+		names[i] = strings.Join(strings.Fields(name), "_")
+		if len(names[i])+1 > width {
+			width = len(names[i]) + 1
+		}
+	}
+	for i := range names {
+		names[i] += strings.Repeat(" ", width-len(names[i]))
+	}
+	return names, width
+}
+
+//AlignmentLength returns the number of columns of the alignment.
+func AlignmentLength(msa []string) int {
+	if len(msa) == 0 {
+		return 0
+	}
+	return len(msa[0])
+}
+
+//ConservedColumn returns true if every sequence has the same residue, and not a gap, in the column.
+func ConservedColumn(msa []string, col int) bool {
+	if msa[0][col] == '-' {
+		return false
+	}
+	for i := 1; i < len(msa); i++ {
+		if msa[i][col] != msa[0][col] {
+			return false
+		}
+	}
+	return true
+}
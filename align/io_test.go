@@ -0,0 +1,107 @@
+package align
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAlignment(t *testing.T) {
+	names := []string{"seq one", "s2"}
+	msa := []string{"AC-GT", "ACTGT"}
+	tests := []struct {
+		name  string
+		write func(w *bytes.Buffer)
+		want  string
+	}{
+		{"fasta", func(w *bytes.Buffer) { WriteFasta(w, names, msa, 3) },
+			">seq one\nAC-\nGT\n>s2\nACT\nGT\n"},
+		{"phylip-sequential", func(w *bytes.Buffer) { WritePhylip(w, names, msa, false) },
+			"2 5\nseq_one   AC-GT\ns2        ACTGT\n"},
+		{"phylip", func(w *bytes.Buffer) { WritePhylip(w, names, msa, true) },
+			"2 5\nseq_one   AC-GT\ns2        ACTGT\n"},
+		{"clustal", func(w *bytes.Buffer) { WriteClustal(w, names, msa) },
+			"CLUSTAL W multiple sequence alignment\n\n\n" +
+				"seq_one         AC-GT\n" +
+				"s2              ACTGT\n" +
+				"                ** **\n"},
+		{"stockholm", func(w *bytes.Buffer) { WriteStockholm(w, names, msa) },
+			"# STOCKHOLM 1.0\n\nseq_one AC-GT\ns2      ACTGT\n//\n"},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		tc.write(&buf)
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%s:\n%s\nwant\n%s", tc.name, got, tc.want)
+		}
+
+		//the file has the same content, except FASTA, which is written with 60 residues per line
+		if tc.name == "fasta" {
+			continue
+		}
+		filename := filepath.Join(t.TempDir(), "msa"+AlignmentFormats[tc.name])
+		if err := WriteAlignmentToFile(names, msa, tc.name, filename); err != nil {
+			t.Fatal(err)
+		}
+		if data, err := os.ReadFile(filename); err != nil || string(data) != tc.want {
+			t.Errorf("%s file:\n%s\nwant\n%s", tc.name, data, tc.want)
+		}
+	}
+
+	if err := WriteAlignmentToFile(names, msa, "nexus", filepath.Join(t.TempDir(), "msa.nex")); err == nil {
+		t.Error("WriteAlignmentToFile accepted the format nexus")
+	}
+}
+
+func TestWriteAlignmentBlocks(t *testing.T) {
+	//70 columns are written in a block of 60 and one of 10
+	names := []string{"a", "b"}
+	msa := []string{strings.Repeat("A", 70), strings.Repeat("C", 60) + strings.Repeat("-", 10)}
+
+	var buf bytes.Buffer
+	WritePhylip(&buf, names, msa, true)
+	want := "2 70\n" +
+		"a         " + strings.Repeat("A", 60) + "\n" +
+		"b         " + strings.Repeat("C", 60) + "\n" +
+		"\n" +
+		"          " + strings.Repeat("A", 10) + "\n" +
+		"          " + strings.Repeat("-", 10) + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("interleaved PHYLIP:\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	WriteClustal(&buf, names, []string{strings.Repeat("A", 70), strings.Repeat("A", 65) + "CCCCC"})
+	want = "CLUSTAL W multiple sequence alignment\n\n" +
+		"\na               " + strings.Repeat("A", 60) +
+		"\nb               " + strings.Repeat("A", 60) +
+		"\n                " + strings.Repeat("*", 60) + "\n" +
+		"\na               " + strings.Repeat("A", 10) +
+		"\nb               " + "AAAAACCCCC" +
+		"\n                " + "*****     " + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Clustal:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMultipleAlignmentOrder(t *testing.T) {
+	genes := []string{"TTGCA", "ACGTACGT", "ACGTTACGT", "ACGACGT"}
+	scores := MatchMatrix(1, -1)
+	if center := MaxRow(ScoreMatrix(genes, Global, scores, 0, -2)); center == 0 {
+		t.Fatal("the center gene is the first gene")
+	}
+	msa := MultipleAlignment(genes, Global, scores, 0, -2)
+	if len(msa) != len(genes) {
+		t.Fatalf("%d rows, want %d", len(msa), len(genes))
+	}
+	for i, row := range msa {
+		if len(row) != len(msa[0]) {
+			t.Errorf("rows of lengths %d and %d", len(msa[0]), len(row))
+		}
+		if RemoveGaps(row) != genes[i] {
+			t.Errorf("row %d is %s, want the gene %s", i, row, genes[i])
+		}
+	}
+}
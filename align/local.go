@@ -0,0 +1,333 @@
+package align
+
+import (
+	"fmt"
+	"strings"
+)
+
+//Local (Smith-Waterman) and semi-global pairwise alignment
+//A local alignment is the best scoring alignment of a part of gene1 with a part of gene2: a cell of the matrix
+//can start a new alignment with a score of 0, and the alignment ends at the best cell anywhere in the matrix.
+//A semi-global alignment aligns both genes but does not score gaps before the start or after the end of either
+//gene, so genes with overhanging ends (e.g. transcripts with UTRs of different lengths) are aligned on their overlap.
+//Both use the three matrices of Gotoh's algorithm, so gaps can be affine; with gapOpen = 0 gaps are linear.
+//Genes whose matrices would have more than HirschbergThreshold cells are aligned in linear memory: a pass that keeps
+//two rows of the matrices finds where the best alignment ends, a pass over the reversed genes from that end finds
+//where it starts, and the two parts of the genes in between are aligned globally with AlignGenes.
+
+//Pairwise alignment modes
+const (
+	Global     = "global"
+	Local      = "local"
+	SemiGlobal = "semiglobal"
+)
+
+//PairwiseAlignment is the alignment of a part of gene1 with a part of gene2.
+//Aligned holds the two aligned sequences, which are gene1[Start1:End1] and gene2[Start2:End2] with gaps.
+type PairwiseAlignment struct {
+	Aligned      []string
+	Start1, End1 int
+	Start2, End2 int
+	Score        int
+}
+
+//AlignPair takes two genes, an alignment mode (global, local or semiglobal) and the scores, and returns their alignment.
+func AlignPair(gene1, gene2, mode string, scores *SubstitutionMatrix, gapOpen, gapExtend int) PairwiseAlignment {
+	switch mode {
+	case Local:
+		return SmithWaterman(gene1, gene2, scores, gapOpen, gapExtend)
+	case SemiGlobal:
+		return SemiGlobalAlign(gene1, gene2, scores, gapOpen, gapExtend)
+	}
+	alignment := AlignGenes(gene1, gene2, scores, gapOpen, gapExtend)
+	return PairwiseAlignment{
+		Aligned: alignment,
+		End1:    len(gene1),
+		End2:    len(gene2),
+		Score:   AlignmentScore(alignment, scores, gapOpen, gapExtend),
+	}
+}
+
+//AlignPadded takes two genes, an alignment mode and the scores, and returns an alignment of the whole genes:
+//the parts of the genes outside a local or semi-global alignment are added against gaps at both ends.
+func AlignPadded(gene1, gene2, mode string, scores *SubstitutionMatrix, gapOpen, gapExtend int) []string {
+	if mode == Global || mode == "" {
+		return AlignGenes(gene1, gene2, scores, gapOpen, gapExtend)
+	}
+	return PadAlignment(gene1, gene2, AlignPair(gene1, gene2, mode, scores, gapOpen, gapExtend))
+}
+
+//PadAlignment adds the parts of gene1 and gene2 before and after a pairwise alignment against gaps.
+func PadAlignment(gene1, gene2 string, a PairwiseAlignment) []string {
+	var align1, align2 strings.Builder
+	align1.WriteString(gene1[:a.Start1])
+	align1.WriteString(strings.Repeat("-", a.Start2))
+	align2.WriteString(strings.Repeat("-", a.Start1))
+	align2.WriteString(gene2[:a.Start2])
+
+	align1.WriteString(a.Aligned[0])
+	align2.WriteString(a.Aligned[1])
+
+	align1.WriteString(gene1[a.End1:])
+	align1.WriteString(strings.Repeat("-", len(gene2)-a.End2))
+	align2.WriteString(strings.Repeat("-", len(gene1)-a.End1))
+	align2.WriteString(gene2[a.End2:])
+	return []string{align1.String(), align2.String()}
+}
+
+//SmithWaterman takes two genes and the scores and returns their best local alignment.
+//If no pair of letters has a positive score, the alignment is empty with a score of 0.
+func SmithWaterman(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int) PairwiseAlignment {
+	return FreeEndAlign(gene1, gene2, scores, gapOpen, gapExtend, true)
+}
+
+//SemiGlobalAlign takes two genes and the scores and returns their best alignment without end gap penalties.
+//The alignment covers the genes from the first to the last aligned letter; the overhangs are left out.
+func SemiGlobalAlign(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int) PairwiseAlignment {
+	return FreeEndAlign(gene1, gene2, scores, gapOpen, gapExtend, false)
+}
+
+//FreeEndAlign fills in the M, X and Y matrices of a local (local = true) or semi-global alignment
+//and traces back the alignment from its best cell. Above HirschbergThreshold cells it calls FreeEndAlignLinear.
+func FreeEndAlign(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int, local bool) PairwiseAlignment {
+	n := len(gene1)
+	l := len(gene2)
+	if (n+1)*(l+1) > HirschbergThreshold {
+		return FreeEndAlignLinear(gene1, gene2, scores, gapOpen, gapExtend, local)
+	}
+	m := NewIntMatrix(n+1, l+1)
+	x := NewIntMatrix(n+1, l+1)
+	y := NewIntMatrix(n+1, l+1)
+
+	//A local alignment starts inside the matrix, so the first row and column are never used.
+	//A semi-global alignment can start after any prefix of either gene for free.
+	m[0][0] = negInf
+	x[0][0] = negInf
+	y[0][0] = negInf
+	if !local {
+		m[0][0] = 0
+	}
+	for r := 1; r <= n; r++ {
+		m[r][0] = negInf
+		x[r][0] = negInf
+		y[r][0] = negInf
+		if !local {
+			x[r][0] = 0
+		}
+	}
+	for c := 1; c <= l; c++ {
+		m[0][c] = negInf
+		x[0][c] = negInf
+		y[0][c] = negInf
+		if !local {
+			y[0][c] = 0
+		}
+	}
+
+	for r := 1; r <= n; r++ {
+		for c := 1; c <= l; c++ {
+			prev := MaxBtwThree(m[r-1][c-1], x[r-1][c-1], y[r-1][c-1])
+			if local && prev < 0 {
+				prev = 0 //start a new local alignment
+			}
+			m[r][c] = prev + scores.Score(gene1[r-1], gene2[c-1])
+			x[r][c] = MaxBtwThree(m[r-1][c]+gapOpen+gapExtend, x[r-1][c]+gapExtend, y[r-1][c]+gapOpen+gapExtend)
+			y[r][c] = MaxBtwThree(m[r][c-1]+gapOpen+gapExtend, y[r][c-1]+gapExtend, x[r][c-1]+gapOpen+gapExtend)
+		}
+	}
+
+	//A local alignment ends at the best M cell; a semi-global alignment ends in the last row or column,
+	//leaving the rest of the other gene as free end gaps.
+	bestR, bestC := 0, 0
+	best := 0
+	state := byte('M')
+	if local {
+		for r := 1; r <= n; r++ {
+			for c := 1; c <= l; c++ {
+				if m[r][c] > best {
+					best, bestR, bestC = m[r][c], r, c
+				}
+			}
+		}
+	} else {
+		best = negInf
+		for r := 0; r <= n; r++ {
+			for c := 0; c <= l; c++ {
+				if r != n && c != l {
+					continue
+				}
+				s := GotohBestState(m[r][c], y[r][c], x[r][c])
+				score := MaxBtwThree(m[r][c], y[r][c], x[r][c])
+				if score > best {
+					best, bestR, bestC, state = score, r, c, s
+				}
+			}
+		}
+	}
+
+	align1 := make([]byte, 0, n+l)
+	align2 := make([]byte, 0, n+l)
+	r, c := bestR, bestC
+	for r > 0 && c > 0 {
+		if state == 'M' {
+			prev := m[r][c] - scores.Score(gene1[r-1], gene2[c-1])
+			align1 = append(align1, gene1[r-1])
+			align2 = append(align2, gene2[c-1])
+			if local && prev == 0 {
+				r--
+				c--
+				break
+			}
+			state = GotohPrevState(prev, m[r-1][c-1], y[r-1][c-1], x[r-1][c-1])
+			r--
+			c--
+		} else if state == 'X' {
+			state = GotohPrevState(x[r][c], m[r-1][c]+gapOpen+gapExtend, y[r-1][c]+gapOpen+gapExtend, x[r-1][c]+gapExtend)
+			align1 = append(align1, gene1[r-1])
+			align2 = append(align2, '-')
+			r--
+		} else {
+			state = GotohPrevState(y[r][c], m[r][c-1]+gapOpen+gapExtend, y[r][c-1]+gapExtend, x[r][c-1]+gapOpen+gapExtend)
+			align1 = append(align1, '-')
+			align2 = append(align2, gene2[c-1])
+			c--
+		}
+	}
+
+	return PairwiseAlignment{
+		Aligned: []string{ReverseSeq(string(align1)), ReverseSeq(string(align2))},
+		Start1:  r,
+		End1:    bestR,
+		Start2:  c,
+		End2:    bestC,
+		Score:   best,
+	}
+}
+
+//FreeEndAlignLinear returns the same local or semi-global alignment score and end as FreeEndAlign, using memory
+//linear in the length of the genes. When several alignments have the best score, it can return another one.
+func FreeEndAlignLinear(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int, local bool) PairwiseAlignment {
+	best, end1, end2 := FreeEndScore(gene1, gene2, scores, gapOpen, gapExtend, local)
+	start1, start2 := FreeEndStart(gene1[:end1], gene2[:end2], scores, gapOpen, gapExtend, best, local)
+	return PairwiseAlignment{
+		Aligned: AlignGenes(gene1[start1:end1], gene2[start2:end2], scores, gapOpen, gapExtend),
+		Start1:  start1,
+		End1:    end1,
+		Start2:  start2,
+		End2:    end2,
+		Score:   best,
+	}
+}
+
+//FreeEndScore fills in the M, X and Y matrices of a local or semi-global alignment two rows at a time and returns
+//the best score and the cell where FreeEndAlign ends the alignment.
+func FreeEndScore(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int, local bool) (int, int, int) {
+	n := len(gene1)
+	l := len(gene2)
+	mPrev, xPrev, yPrev := make([]int, l+1), make([]int, l+1), make([]int, l+1)
+	mCurr, xCurr, yCurr := make([]int, l+1), make([]int, l+1), make([]int, l+1)
+	for c := range mPrev {
+		mPrev[c], xPrev[c], yPrev[c] = negInf, negInf, negInf
+		if !local && c > 0 {
+			yPrev[c] = 0
+		}
+	}
+	if !local {
+		mPrev[0] = 0
+	}
+
+	bestR, bestC := 0, 0
+	best := 0
+	if !local {
+		//in the first row, the last cell ends an alignment, or all of them if gene1 is empty
+		best = negInf
+		for c := 0; c <= l; c++ {
+			if n != 0 && c != l {
+				continue
+			}
+			if score := MaxBtwThree(mPrev[c], yPrev[c], xPrev[c]); score > best {
+				best, bestC = score, c
+			}
+		}
+	}
+	for r := 1; r <= n; r++ {
+		mCurr[0], xCurr[0], yCurr[0] = negInf, negInf, negInf
+		if !local {
+			xCurr[0] = 0
+		}
+		for c := 1; c <= l; c++ {
+			prev := MaxBtwThree(mPrev[c-1], xPrev[c-1], yPrev[c-1])
+			if local && prev < 0 {
+				prev = 0 //start a new local alignment
+			}
+			mCurr[c] = prev + scores.Score(gene1[r-1], gene2[c-1])
+			xCurr[c] = MaxBtwThree(mPrev[c]+gapOpen+gapExtend, xPrev[c]+gapExtend, yPrev[c]+gapOpen+gapExtend)
+			yCurr[c] = MaxBtwThree(mCurr[c-1]+gapOpen+gapExtend, yCurr[c-1]+gapExtend, xCurr[c-1]+gapOpen+gapExtend)
+		}
+		//the cells are compared in the same order as in FreeEndAlign
+		for c := 0; c <= l; c++ {
+			if local {
+				if c > 0 && mCurr[c] > best {
+					best, bestR, bestC = mCurr[c], r, c
+				}
+			} else if r == n || c == l {
+				if score := MaxBtwThree(mCurr[c], yCurr[c], xCurr[c]); score > best {
+					best, bestR, bestC = score, r, c
+				}
+			}
+		}
+		mPrev, mCurr = mCurr, mPrev
+		xPrev, xCurr = xCurr, xPrev
+		yPrev, yCurr = yCurr, yPrev
+	}
+	return best, bestR, bestC
+}
+
+//FreeEndStart takes the parts of gene1 and gene2 before the end of a local or semi-global alignment with score best,
+//and returns where in them the alignment starts: a local alignment starts with two aligned letters anywhere, a
+//semi-global one at the start of either gene. It fills in the matrices of the reversed parts from the end of the
+//alignment two rows at a time, until the whole of what is left aligns with score best.
+func FreeEndStart(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend, best int, local bool) (int, int) {
+	rev1 := ReverseSeq(gene1)
+	rev2 := ReverseSeq(gene2)
+	n := len(rev1)
+	l := len(rev2)
+	mPrev, xPrev, yPrev := make([]int, l+1), make([]int, l+1), make([]int, l+1)
+	mCurr, xCurr, yCurr := make([]int, l+1), make([]int, l+1), make([]int, l+1)
+	mPrev[0], xPrev[0], yPrev[0] = 0, negInf, negInf
+	for c := 1; c <= l; c++ {
+		mPrev[c], xPrev[c], yPrev[c] = negInf, negInf, gapOpen+c*gapExtend
+	}
+	for r := 0; r <= n; r++ {
+		if r > 0 {
+			mCurr[0], xCurr[0], yCurr[0] = negInf, gapOpen+r*gapExtend, negInf
+			for c := 1; c <= l; c++ {
+				mCurr[c] = MaxBtwThree(mPrev[c-1], xPrev[c-1], yPrev[c-1]) + scores.Score(rev1[r-1], rev2[c-1])
+				xCurr[c] = MaxBtwThree(mPrev[c]+gapOpen+gapExtend, xPrev[c]+gapExtend, yPrev[c]+gapOpen+gapExtend)
+				yCurr[c] = MaxBtwThree(mCurr[c-1]+gapOpen+gapExtend, yCurr[c-1]+gapExtend, xCurr[c-1]+gapOpen+gapExtend)
+			}
+			mPrev, mCurr = mCurr, mPrev
+			xPrev, xCurr = xCurr, xPrev
+			yPrev, yCurr = yCurr, yPrev
+		}
+		for c := 0; c <= l; c++ {
+			if local && r > 0 && c > 0 && mPrev[c] == best {
+				return n - r, l - c
+			}
+			if !local && (r == n || c == l) && MaxBtwThree(mPrev[c], xPrev[c], yPrev[c]) == best {
+				return n - r, l - c
+			}
+		}
+	}
+	//an empty local alignment
+	return n, l
+}
+
+//PrintPairwiseAlignment prints out the names, 1-based coordinates and score of a pairwise alignment,
+//followed by the two aligned sequences.
+func PrintPairwiseAlignment(name1, name2 string, a PairwiseAlignment) {
+	names, _ := PaddedNames([]string{name1, name2}, 0)
+	fmt.Println(name1, a.Start1+1, "-", a.End1, "vs", name2, a.Start2+1, "-", a.End2, "score:", a.Score)
+	fmt.Println(names[0], a.Aligned[0])
+	fmt.Println(names[1], a.Aligned[1])
+}
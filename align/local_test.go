@@ -0,0 +1,54 @@
+package align
+
+import (
+	"math/rand"
+	"testing"
+)
+
+//checkFreeEndAlignment fails the test unless a is an alignment of the parts of gene1 and gene2 it gives, with its score.
+func checkFreeEndAlignment(t *testing.T, a PairwiseAlignment, gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int) {
+	t.Helper()
+	if len(a.Aligned[0]) != len(a.Aligned[1]) {
+		t.Fatalf("aligned genes have lengths %d and %d", len(a.Aligned[0]), len(a.Aligned[1]))
+	}
+	if RemoveGaps(a.Aligned[0]) != gene1[a.Start1:a.End1] || RemoveGaps(a.Aligned[1]) != gene2[a.Start2:a.End2] {
+		t.Fatalf("%v is not an alignment of %s and %s", a.Aligned, gene1[a.Start1:a.End1], gene2[a.Start2:a.End2])
+	}
+	if score := AlignmentScore(a.Aligned, scores, gapOpen, gapExtend); score != a.Score {
+		t.Errorf("alignment scores %d, not %d", score, a.Score)
+	}
+}
+
+func TestFreeEndAlignLinear(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	scores := MatchMatrix(2, -1)
+	pairs := [][2]string{{"", ""}, {"", "ACGT"}, {"ACGT", ""}, {"AAAA", "CCCC"}, {"A", "ACGT"}, {"ACGT", "T"}}
+	for _, length := range []int{50, 200, 500} {
+		gene1, gene2 := randomGenePair(length, rng)
+		//overhanging ends of unrelated sequence on both genes
+		flank1, flank2 := randomGenePair(length/5, rng)
+		flank3, _ := randomGenePair(length/3, rng)
+		pairs = append(pairs, [2]string{flank1 + gene1, gene2 + flank2}, [2]string{flank3 + gene1 + flank1, gene2})
+	}
+	for _, gaps := range [][2]int{{0, -2}, {-5, -1}} {
+		for _, local := range []bool{true, false} {
+			for _, pair := range pairs {
+				full := FreeEndAlign(pair[0], pair[1], scores, gaps[0], gaps[1], local)
+				linear := FreeEndAlignLinear(pair[0], pair[1], scores, gaps[0], gaps[1], local)
+				if linear.Score != full.Score || linear.End1 != full.End1 || linear.End2 != full.End2 {
+					t.Errorf("local %v, gaps %v: linear score %d ending at %d, %d, full matrices %d ending at %d, %d",
+						local, gaps, linear.Score, linear.End1, linear.End2, full.Score, full.End1, full.End2)
+				}
+				checkFreeEndAlignment(t, full, pair[0], pair[1], scores, gaps[0], gaps[1])
+				checkFreeEndAlignment(t, linear, pair[0], pair[1], scores, gaps[0], gaps[1])
+			}
+		}
+	}
+
+	//FreeEndAlign switches to the linear path above the threshold
+	defer func(threshold int) { HirschbergThreshold = threshold }(HirschbergThreshold)
+	HirschbergThreshold = 100
+	for _, pair := range pairs {
+		checkFreeEndAlignment(t, SmithWaterman(pair[0], pair[1], scores, -5, -1), pair[0], pair[1], scores, -5, -1)
+	}
+}
@@ -0,0 +1,113 @@
+package align
+
+//Linear-memory pairwise alignment with affine gaps -- Myers and Miller's algorithm
+//Gotoh keeps three full matrices, which do not fit in memory for long genes. Like Hirschberg's algorithm,
+//Myers and Miller's algorithm scores the top half of gene1 forwards and the bottom half backwards, keeping
+//only two rows of each matrix, and splits the problem where an optimal alignment crosses the middle row.
+//With affine gaps an optimal alignment can also cross the middle row inside a gap in gene2. Both halves
+//then count the opening of that gap, so it is counted once less, and the two halves are aligned so that
+//the top one ends with the gap and the bottom one extends it.
+//As with Hirschberg, the alignment has the same optimal score as Gotoh, not necessarily the same alignment.
+
+//MyersMiller takes two genes and the scores for an affine-gap alignment and returns an optimal alignment of
+//the two genes, using memory linear in the length of the genes.
+func MyersMiller(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int) []string {
+	align1 := make([]byte, 0, len(gene1)+len(gene2))
+	align2 := make([]byte, 0, len(gene1)+len(gene2))
+	align1, align2 = MyersMillerAlign(gene1, gene2, scores, gapOpen, gapExtend, 'M', 'M', align1, align2)
+	return []string{string(align1), string(align2)}
+}
+
+//MyersMillerAlign appends an optimal alignment of gene1 and gene2 to align1 and align2 and returns them.
+//If start is 'X', the alignment follows a gap in gene2, which a gap in gene2 at its start extends. If end is 'X',
+//the alignment ends with a gap in gene2.
+func MyersMillerAlign(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int, start, end byte, align1, align2 []byte) ([]byte, []byte) {
+	n := len(gene1)
+	m := len(gene2)
+	//small problems are solved with the full matrices
+	if n <= 1 || m <= 1 || (n+1)*(m+1) <= hirschbergBaseCells {
+		mm, x, y := GotohMatricesFrom(gene1, gene2, scores, gapOpen, gapExtend, start)
+		state := GotohBestState(mm[n][m], y[n][m], x[n][m])
+		if end == 'X' {
+			state = 'X'
+		}
+		pAlign := GotohTracebackFrom(gene1, gene2, scores, gapOpen, gapExtend, mm, x, y, state)
+		return append(align1, pAlign[0]...), append(align2, pAlign[1]...)
+	}
+
+	mid := n / 2
+	//scores of the top half of gene1 against every prefix of gene2, and of those ending with a gap in gene2
+	forward, forwardGap := GotohLastRow(gene1[:mid], gene2, scores, gapOpen, gapExtend, start)
+	//scores of the bottom half of gene1 against every suffix of gene2, and of those starting with a gap in gene2
+	var backward, backwardGap []int
+	if end == 'X' {
+		//the last nucleotide of gene1 is against a gap, which the rest of the bottom half may extend
+		backward, backwardGap = GotohLastRow(ReverseSeq(gene1[mid:n-1]), ReverseSeq(gene2), scores, gapOpen, gapExtend, 'X')
+		for c := range backward {
+			backward[c] += gapOpen + gapExtend
+			backwardGap[c] += gapOpen + gapExtend
+		}
+	} else {
+		backward, backwardGap = GotohLastRow(ReverseSeq(gene1[mid:]), ReverseSeq(gene2), scores, gapOpen, gapExtend, 'M')
+	}
+
+	//an optimal alignment crosses the middle row at the column with the best total score, either between two
+	//columns or inside a gap in gene2 whose opening both halves counted
+	split := 0
+	inGap := false
+	best := forward[0] + backward[m]
+	for j := 0; j <= m; j++ {
+		if forward[j]+backward[m-j] > best {
+			split, inGap = j, false
+			best = forward[j] + backward[m-j]
+		}
+		if forwardGap[j]+backwardGap[m-j]-gapOpen > best {
+			split, inGap = j, true
+			best = forwardGap[j] + backwardGap[m-j] - gapOpen
+		}
+	}
+
+	if inGap {
+		align1, align2 = MyersMillerAlign(gene1[:mid], gene2[:split], scores, gapOpen, gapExtend, start, 'X', align1, align2)
+		return MyersMillerAlign(gene1[mid:], gene2[split:], scores, gapOpen, gapExtend, 'X', end, align1, align2)
+	}
+	align1, align2 = MyersMillerAlign(gene1[:mid], gene2[:split], scores, gapOpen, gapExtend, start, 'M', align1, align2)
+	return MyersMillerAlign(gene1[mid:], gene2[split:], scores, gapOpen, gapExtend, 'M', end, align1, align2)
+}
+
+//GotohLastRow takes two genes, the scores for an affine-gap alignment and the state the alignment follows, as in
+//GotohMatricesFrom, and returns the best of the last rows of the M, X and Y matrices and the last row of X,
+//keeping only two rows of each matrix in memory.
+func GotohLastRow(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int, start byte) ([]int, []int) {
+	cols := len(gene2) + 1
+	mPrev, xPrev, yPrev := make([]int, cols), make([]int, cols), make([]int, cols)
+	mCurr, xCurr, yCurr := make([]int, cols), make([]int, cols), make([]int, cols)
+
+	//the first row can only be reached by gaps in gene1
+	mPrev[0], xPrev[0], yPrev[0] = 0, negInf, negInf
+	open := gapOpen
+	if start == 'X' {
+		xPrev[0] = 0
+		open = 0
+	}
+	for c := 1; c < cols; c++ {
+		mPrev[c], xPrev[c], yPrev[c] = negInf, negInf, gapOpen+c*gapExtend
+	}
+	for r := 1; r <= len(gene1); r++ {
+		mCurr[0], xCurr[0], yCurr[0] = negInf, open+r*gapExtend, negInf
+		for c := 1; c < cols; c++ {
+			mCurr[c] = MaxBtwThree(mPrev[c-1], xPrev[c-1], yPrev[c-1]) + scores.Score(gene1[r-1], gene2[c-1])
+			xCurr[c] = MaxBtwThree(mPrev[c]+gapOpen+gapExtend, xPrev[c]+gapExtend, yPrev[c]+gapOpen+gapExtend)
+			yCurr[c] = MaxBtwThree(mCurr[c-1]+gapOpen+gapExtend, yCurr[c-1]+gapExtend, xCurr[c-1]+gapOpen+gapExtend)
+		}
+		mPrev, mCurr = mCurr, mPrev
+		xPrev, xCurr = xCurr, xPrev
+		yPrev, yCurr = yCurr, yPrev
+	}
+
+	best := make([]int, cols)
+	for c := range best {
+		best[c] = MaxBtwThree(mPrev[c], xPrev[c], yPrev[c])
+	}
+	return best, xPrev
+}
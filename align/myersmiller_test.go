@@ -0,0 +1,51 @@
+package align
+
+import (
+	"math/rand"
+	"testing"
+)
+
+//gotohScore returns the optimal score of an affine-gap alignment of gene1 and gene2.
+func gotohScore(gene1, gene2 string, scores *SubstitutionMatrix, gapOpen, gapExtend int) int {
+	m, x, y := GotohMatrices(gene1, gene2, scores, gapOpen, gapExtend)
+	return MaxBtwThree(m[len(gene1)][len(gene2)], x[len(gene1)][len(gene2)], y[len(gene1)][len(gene2)])
+}
+
+func TestMyersMillerIsOptimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	pairs := [][2]string{{"", "ACGT"}, {"ACGT", ""}, {"A", "ACGT"}, {"ACGT", "A"}, {"AAAAAAAA", "AA"}, {"ACACAC", "CACA"}}
+	for _, length := range []int{50, 100, 300, 1000} {
+		gene1, gene2 := randomGenePair(length, rng)
+		pairs = append(pairs, [2]string{gene1, gene2})
+	}
+	//long gaps in one gene, so that optimal alignments cross the middle row inside a gap
+	gene1, _ := randomGenePair(400, rng)
+	pairs = append(pairs, [2]string{gene1, gene1[:50] + gene1[350:]}, [2]string{gene1[100:300], gene1})
+
+	for _, gaps := range [][2]int{{-10, -1}, {-3, -2}, {-1, -1}} {
+		scores := MatchMatrix(2, -1)
+		for _, pair := range pairs {
+			msa := MyersMiller(pair[0], pair[1], scores, gaps[0], gaps[1])
+			if len(msa[0]) != len(msa[1]) || RemoveGaps(msa[0]) != pair[0] || RemoveGaps(msa[1]) != pair[1] {
+				t.Fatalf("%v is not an alignment of %s and %s", msa, pair[0], pair[1])
+			}
+			optimal := gotohScore(pair[0], pair[1], scores, gaps[0], gaps[1])
+			if score := AlignmentScore(msa, scores, gaps[0], gaps[1]); score != optimal {
+				t.Errorf("gaps %v: alignment of genes of lengths %d and %d scores %d, optimum %d",
+					gaps, len(pair[0]), len(pair[1]), score, optimal)
+			}
+		}
+	}
+}
+
+func BenchmarkGotoh(b *testing.B) {
+	benchmarkAlignment(b, func(gene1, gene2 string, scores *SubstitutionMatrix) {
+		Gotoh(gene1, gene2, scores, -10, -1)
+	})
+}
+
+func BenchmarkMyersMiller(b *testing.B) {
+	benchmarkAlignment(b, func(gene1, gene2 string, scores *SubstitutionMatrix) {
+		MyersMiller(gene1, gene2, scores, -10, -1)
+	})
+}
@@ -0,0 +1,319 @@
+package align
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/nj"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+//Multiple Sequence Alignment -- progressive method
+//The pairwise scores of ScoreMatrix are turned into distances, a guide tree is built from them with the nj package's
+//neighbor joining or UPGMA, and the genes are aligned up the tree: at each internal node the alignments
+//(profiles) of its two subtrees are aligned to each other column by column with sum-of-pairs scores.
+//Unlike the star method, gaps found while aligning two non-center genes are kept in the final alignment.
+
+//Multiple alignment methods
+const (
+	Star        = "star"
+	Progressive = "progressive"
+)
+
+//Guide tree methods
+const (
+	GuideNJ    = "nj"
+	GuideUPGMA = "upgma"
+)
+
+//GuideNode is a node of a rooted binary guide tree. Leaves have the index of their gene and no children;
+//internal nodes have a Leaf of -1.
+type GuideNode struct {
+	Leaf           int
+	Child1, Child2 *GuideNode
+}
+
+//Leaves returns the gene indices of the leaves below a node, from left to right.
+func (node *GuideNode) Leaves() []int {
+	if node.Leaf >= 0 {
+		return []int{node.Leaf}
+	}
+	return append(node.Child1.Leaves(), node.Child2.Leaves()...)
+}
+
+//ProfileColumn is one column of a profile: each letter in the column with the number of rows that have it,
+//and the number of rows with a gap.
+type ProfileColumn struct {
+	Letters []byte
+	Counts  []int
+	Gaps    int
+}
+
+//ProgressiveAlignment takes genes, a guide tree method (nj or upgma), the pairwise alignment mode used for
+//the guide tree and the alignment scores, and returns the genes after progressive alignment in input order.
+//It returns an error if the guide tree can't be built.
+func ProgressiveAlignment(genes []string, guide, mode string, scores *SubstitutionMatrix, gapOpen, gapExtend int) ([]string, error) {
+	if len(genes) == 1 {
+		return []string{genes[0]}, nil
+	}
+	dist := GuideDistances(genes, ScoreMatrix(genes, mode, scores, gapOpen, gapExtend), scores)
+	root, err := GuideTree(dist, guide)
+	if err != nil {
+		return nil, err
+	}
+	profile := AlignGuideTree(root, genes, scores, gapOpen, gapExtend)
+
+	//the rows of the profile follow the leaves of the guide tree, put them back in input order
+	msa := make([]string, len(genes))
+	for i, leaf := range root.Leaves() {
+		msa[leaf] = profile[i]
+	}
+	return msa, nil
+}
+
+//GuideDistances takes genes, their ScoreMatrix and the substitution matrix, and returns the distance between each
+//pair of genes: the mean of the scores of the two genes against themselves minus the score of their alignment.
+func GuideDistances(genes []string, scorematrix [][]int, scores *SubstitutionMatrix) [][]float64 {
+	self := make([]int, len(genes))
+	for i, gene := range genes {
+		for k := 0; k < len(gene); k++ {
+			self[i] += scores.Score(gene[k], gene[k])
+		}
+	}
+	dist := make([][]float64, len(genes))
+	for i := range dist {
+		dist[i] = make([]float64, len(genes))
+		for j := range dist[i] {
+			if i != j {
+				dist[i][j] = float64(self[i]+self[j])/2 - float64(scorematrix[i][j])
+			}
+		}
+	}
+	return dist
+}
+
+//GuideTree takes a distance matrix and a guide tree method (nj or upgma) and returns the guide tree, built with
+//nj.NeighborJoining or nj.UPGMA. The neighbor-joining tree is rooted at its last join.
+func GuideTree(dist [][]float64, guide string) (*GuideNode, error) {
+	switch len(dist) {
+	case 1:
+		return &GuideNode{Leaf: 0}, nil
+	case 2:
+		return &GuideNode{Leaf: -1, Child1: &GuideNode{Leaf: 0}, Child2: &GuideNode{Leaf: 1}}, nil
+	}
+	names := make([]string, len(dist))
+	index := make(map[string]int, len(dist))
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+		index[names[i]] = i
+	}
+	var t nj.Tree
+	var err error
+	if guide == GuideUPGMA {
+		t, _, err = nj.UPGMA(dist, names)
+	} else {
+		t, err = nj.NeighborJoining(dist, names)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("guide tree: %w", err)
+	}
+	return NewGuideNode(t.ToTree().Root, index), nil
+}
+
+//NewGuideNode takes a node of a tree whose leaves are named by gene index and returns it as a guide tree node.
+//A node with more than two children, such as the root of a neighbor-joining tree, whose last child is the node
+//left after the last join, becomes the parent of its last child and of the other children joined in order.
+func NewGuideNode(n *tree.Node, index map[string]int) *GuideNode {
+	if n.IsLeaf() {
+		return &GuideNode{Leaf: index[n.Label]}
+	}
+	last := len(n.Children) - 1
+	node := NewGuideNode(n.Children[0], index)
+	for _, child := range n.Children[1:last] {
+		node = &GuideNode{Leaf: -1, Child1: node, Child2: NewGuideNode(child, index)}
+	}
+	if last > 1 {
+		return &GuideNode{Leaf: -1, Child1: NewGuideNode(n.Children[last], index), Child2: node}
+	}
+	return &GuideNode{Leaf: -1, Child1: node, Child2: NewGuideNode(n.Children[last], index)}
+}
+
+//AlignGuideTree aligns the genes below a node of the guide tree and returns the profile, with the rows in
+//the order of node.Leaves().
+func AlignGuideTree(node *GuideNode, genes []string, scores *SubstitutionMatrix, gapOpen, gapExtend int) []string {
+	if node.Leaf >= 0 {
+		return []string{genes[node.Leaf]}
+	}
+	profile1 := AlignGuideTree(node.Child1, genes, scores, gapOpen, gapExtend)
+	profile2 := AlignGuideTree(node.Child2, genes, scores, gapOpen, gapExtend)
+	return AlignProfiles(profile1, profile2, scores, gapOpen, gapExtend)
+}
+
+//ProfileColumns takes a profile (aligned sequences of equal length) and returns its columns.
+func ProfileColumns(profile []string) []ProfileColumn {
+	columns := make([]ProfileColumn, AlignmentLength(profile))
+	for i := range columns {
+		for _, row := range profile {
+			letter := row[i]
+			if letter == '-' {
+				columns[i].Gaps++
+				continue
+			}
+			found := false
+			for k, l := range columns[i].Letters {
+				if l == letter {
+					columns[i].Counts[k]++
+					found = true
+					break
+				}
+			}
+			if !found {
+				columns[i].Letters = append(columns[i].Letters, letter)
+				columns[i].Counts = append(columns[i].Counts, 1)
+			}
+		}
+	}
+	return columns
+}
+
+//ColumnScore returns the sum-of-pairs score of aligning column a of one profile with column b of another:
+//the substitution score of every pair of letters and gapExtend for every letter against a gap.
+func ColumnScore(a, b ProfileColumn, scores *SubstitutionMatrix, gapExtend int) int {
+	score := 0
+	lettersA := 0
+	lettersB := 0
+	for i, la := range a.Letters {
+		lettersA += a.Counts[i]
+		for j, lb := range b.Letters {
+			score += a.Counts[i] * b.Counts[j] * scores.Score(la, lb)
+		}
+	}
+	for _, count := range b.Counts {
+		lettersB += count
+	}
+	return score + (lettersA*b.Gaps+lettersB*a.Gaps)*gapExtend
+}
+
+//AlignProfiles takes two profiles and the alignment scores and returns the profile of their alignment,
+//the rows of profile1 followed by the rows of profile2. It is Gotoh's algorithm on columns: aligning two
+//columns scores ColumnScore, a column against a new gap column scores gapExtend for each of its letters
+//against each row of the other profile, and opening a gap column costs gapOpen for every pair of rows.
+//For two single genes it is the same as Gotoh.
+func AlignProfiles(profile1, profile2 []string, scores *SubstitutionMatrix, gapOpen, gapExtend int) []string {
+	cols1 := ProfileColumns(profile1)
+	cols2 := ProfileColumns(profile2)
+	n := len(cols1)
+	l := len(cols2)
+	open := len(profile1) * len(profile2) * gapOpen
+
+	//gap1[r] is the score of column r of profile1 against a gap column, gap2[c] of column c of profile2
+	gap1 := make([]int, n)
+	for r, col := range cols1 {
+		gap1[r] = (len(profile1) - col.Gaps) * len(profile2) * gapExtend
+	}
+	gap2 := make([]int, l)
+	for c, col := range cols2 {
+		gap2[c] = (len(profile2) - col.Gaps) * len(profile1) * gapExtend
+	}
+
+	m := NewIntMatrix(n+1, l+1)
+	x := NewIntMatrix(n+1, l+1)
+	y := NewIntMatrix(n+1, l+1)
+	x[0][0] = negInf
+	y[0][0] = negInf
+	//the first row and column are one gap column each, opened once
+	for r := 1; r <= n; r++ {
+		m[r][0] = negInf
+		x[r][0] = open + gap1[r-1]
+		if r > 1 {
+			x[r][0] = x[r-1][0] + gap1[r-1]
+		}
+		y[r][0] = negInf
+	}
+	for c := 1; c <= l; c++ {
+		m[0][c] = negInf
+		x[0][c] = negInf
+		y[0][c] = open + gap2[c-1]
+		if c > 1 {
+			y[0][c] = y[0][c-1] + gap2[c-1]
+		}
+	}
+	for r := 1; r <= n; r++ {
+		for c := 1; c <= l; c++ {
+			m[r][c] = MaxBtwThree(m[r-1][c-1], x[r-1][c-1], y[r-1][c-1]) + ColumnScore(cols1[r-1], cols2[c-1], scores, gapExtend)
+			x[r][c] = MaxBtwThree(m[r-1][c]+open, x[r-1][c], y[r-1][c]+open) + gap1[r-1]
+			y[r][c] = MaxBtwThree(m[r][c-1]+open, y[r][c-1], x[r][c-1]+open) + gap2[c-1]
+		}
+	}
+
+	//trace back the columns of the alignment, from the end to the start
+	rows := make([][]byte, len(profile1)+len(profile2))
+	for i := range rows {
+		rows[i] = make([]byte, 0, n+l)
+	}
+	r := n
+	c := l
+	state := GotohBestState(m[r][c], y[r][c], x[r][c])
+	for r > 0 || c > 0 {
+		if r == 0 {
+			state = 'Y'
+		} else if c == 0 {
+			state = 'X'
+		}
+		switch state {
+		case 'M':
+			prev := m[r][c] - ColumnScore(cols1[r-1], cols2[c-1], scores, gapExtend)
+			state = GotohPrevState(prev, m[r-1][c-1], y[r-1][c-1], x[r-1][c-1])
+			r--
+			c--
+			AppendColumn(rows, profile1, profile2, r, c)
+		case 'X':
+			prev := x[r][c] - gap1[r-1]
+			state = GotohPrevState(prev, m[r-1][c]+open, y[r-1][c]+open, x[r-1][c])
+			r--
+			AppendColumn(rows, profile1, profile2, r, -1)
+		case 'Y':
+			prev := y[r][c] - gap2[c-1]
+			state = GotohPrevState(prev, m[r][c-1]+open, y[r][c-1], x[r][c-1]+open)
+			c--
+			AppendColumn(rows, profile1, profile2, -1, c)
+		}
+	}
+
+	profile := make([]string, len(rows))
+	for i, row := range rows {
+		profile[i] = ReverseSeq(string(row))
+	}
+	return profile
+}
+
+//AppendColumn appends column r of profile1 and column c of profile2 to the rows of an alignment.
+//A column index of -1 appends a gap to every row of that profile.
+func AppendColumn(rows [][]byte, profile1, profile2 []string, r, c int) {
+	for i, seq := range profile1 {
+		if r < 0 {
+			rows[i] = append(rows[i], '-')
+		} else {
+			rows[i] = append(rows[i], seq[r])
+		}
+	}
+	for i, seq := range profile2 {
+		k := len(profile1) + i
+		if c < 0 {
+			rows[k] = append(rows[k], '-')
+		} else {
+			rows[k] = append(rows[k], seq[c])
+		}
+	}
+}
+
+//SumOfPairsScore returns the sum of the AlignmentScore of every pair of rows of a multiple alignment.
+func SumOfPairsScore(msa []string, scores *SubstitutionMatrix, gapOpen, gapExtend int) int {
+	score := 0
+	for i := 0; i < len(msa); i++ {
+		for j := i + 1; j < len(msa); j++ {
+			score += AlignmentScore([]string{msa[i], msa[j]}, scores, gapOpen, gapExtend)
+		}
+	}
+	return score
+}
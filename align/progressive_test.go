@@ -0,0 +1,63 @@
+package align
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+//guideClades returns the leaves below every node of a guide tree, each sorted and written as a string.
+func guideClades(node *GuideNode, clades map[string]bool) {
+	leaves := node.Leaves()
+	sort.Ints(leaves)
+	clades[fmt.Sprint(leaves)] = true
+	if node.Leaf < 0 {
+		guideClades(node.Child1, clades)
+		guideClades(node.Child2, clades)
+	}
+}
+
+func TestGuideTree(t *testing.T) {
+	//the UPGMA example of Wikipedia, whose tree is (((a,b),e),(c,d))
+	dist := [][]float64{
+		{0, 17, 21, 31, 23},
+		{17, 0, 30, 34, 21},
+		{21, 30, 0, 28, 39},
+		{31, 34, 28, 0, 43},
+		{23, 21, 39, 43, 0},
+	}
+	//additive distances of the unrooted tree ((0,1),2,(3,4)) with branches of length 1
+	additive := [][]float64{
+		{0, 2, 3, 4, 4},
+		{2, 0, 3, 4, 4},
+		{3, 3, 0, 3, 3},
+		{4, 4, 3, 0, 2},
+		{4, 4, 3, 2, 0},
+	}
+	tests := []struct {
+		dist   [][]float64
+		guide  string
+		clades []string
+	}{
+		{dist, GuideUPGMA, []string{"[0 1]", "[0 1 4]", "[2 3]", "[0 1 2 3 4]"}},
+		{additive, GuideNJ, []string{"[0 1]", "[3 4]", "[0 1 2 3 4]"}},
+		{[][]float64{{0}}, GuideNJ, []string{"[0]"}},
+		{[][]float64{{0, 1}, {1, 0}}, GuideUPGMA, []string{"[0 1]"}},
+	}
+	for _, test := range tests {
+		root, err := GuideTree(test.dist, test.guide)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(root.Leaves()) != len(test.dist) {
+			t.Errorf("%s guide tree has %d leaves, not %d", test.guide, len(root.Leaves()), len(test.dist))
+		}
+		clades := make(map[string]bool)
+		guideClades(root, clades)
+		for _, clade := range test.clades {
+			if !clades[clade] {
+				t.Errorf("%s guide tree of %d genes has no clade %s", test.guide, len(test.dist), clade)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package align
+
+//Iterative refinement of a multiple alignment
+//A guide tree is built from the pairwise scores induced by the alignment. Each round goes over the edges of the
+//tree: the genes below the edge and the other genes form two groups, the columns with only gaps are removed
+//from each group, and the two groups are realigned as profiles. The new alignment is kept when its
+//sum-of-pairs score is higher. Refinement stops after a number of rounds or after a round without improvement.
+
+//RefineAlignment takes a multiple alignment, a guide tree method (nj or upgma), the alignment scores and a maximum
+//number of rounds, and returns the refined alignment in the same row order and the sum-of-pairs score after each round.
+//It returns an error if the guide tree can't be built.
+func RefineAlignment(msa []string, guide string, scores *SubstitutionMatrix, gapOpen, gapExtend, maxIterations int) ([]string, []int, error) {
+	rounds := []int{}
+	if len(msa) < 3 {
+		return msa, rounds, nil //with two genes every split realigns the same pair
+	}
+	root, err := MSAGuideTree(msa, guide, scores, gapOpen, gapExtend)
+	if err != nil {
+		return nil, nil, err
+	}
+	edges := GuideEdges(root)
+
+	best := SumOfPairsScore(msa, scores, gapOpen, gapExtend)
+	for round := 0; round < maxIterations; round++ {
+		improved := false
+		for _, node := range edges {
+			candidate := RealignSplit(msa, node.Leaves(), scores, gapOpen, gapExtend)
+			score := SumOfPairsScore(candidate, scores, gapOpen, gapExtend)
+			if score > best {
+				msa = candidate
+				best = score
+				improved = true
+			}
+		}
+		rounds = append(rounds, best)
+		if !improved {
+			break
+		}
+	}
+	return msa, rounds, nil
+}
+
+//MSAGuideTree takes a multiple alignment and returns a guide tree built from the score of each pair of rows.
+func MSAGuideTree(msa []string, guide string, scores *SubstitutionMatrix, gapOpen, gapExtend int) (*GuideNode, error) {
+	genes := make([]string, len(msa))
+	for i, row := range msa {
+		genes[i] = RemoveGaps(row)
+	}
+	scorematrix := make([][]int, len(msa))
+	for i := range scorematrix {
+		scorematrix[i] = make([]int, len(msa))
+	}
+	for i := 0; i < len(msa); i++ {
+		for j := i + 1; j < len(msa); j++ {
+			scorematrix[i][j] = AlignmentScore([]string{msa[i], msa[j]}, scores, gapOpen, gapExtend)
+			scorematrix[j][i] = scorematrix[i][j]
+		}
+	}
+	return GuideTree(GuideDistances(genes, scorematrix, scores), guide)
+}
+
+//GuideEdges returns every node below the root of a guide tree, each standing for the edge above it.
+//The two children of the root split the genes the same way, so only the first one is kept.
+func GuideEdges(root *GuideNode) []*GuideNode {
+	edges := []*GuideNode{}
+	if root.Leaf >= 0 {
+		return edges
+	}
+	CollectGuideEdges(root.Child1, &edges)
+	if root.Child2.Leaf < 0 {
+		CollectGuideEdges(root.Child2.Child1, &edges)
+		CollectGuideEdges(root.Child2.Child2, &edges)
+	}
+	return edges
+}
+
+//CollectGuideEdges appends a node and every node below it to edges.
+func CollectGuideEdges(node *GuideNode, edges *[]*GuideNode) {
+	*edges = append(*edges, node)
+	if node.Leaf < 0 {
+		CollectGuideEdges(node.Child1, edges)
+		CollectGuideEdges(node.Child2, edges)
+	}
+}
+
+//RealignSplit takes a multiple alignment and the indices of the rows in one group, realigns that group
+//with the other rows as two profiles and returns the new alignment in the original row order.
+func RealignSplit(msa []string, group []int, scores *SubstitutionMatrix, gapOpen, gapExtend int) []string {
+	inGroup := make(map[int]bool, len(group))
+	for _, i := range group {
+		inGroup[i] = true
+	}
+	profile1 := []string{}
+	profile2 := []string{}
+	order := make([]int, 0, len(msa))
+	for i, row := range msa {
+		if inGroup[i] {
+			profile1 = append(profile1, row)
+			order = append(order, i)
+		}
+	}
+	for i, row := range msa {
+		if !inGroup[i] {
+			profile2 = append(profile2, row)
+			order = append(order, i)
+		}
+	}
+	aligned := AlignProfiles(RemoveGapColumns(profile1), RemoveGapColumns(profile2), scores, gapOpen, gapExtend)
+	realigned := make([]string, len(msa))
+	for k, i := range order {
+		realigned[i] = aligned[k]
+	}
+	return realigned
+}
+
+//RemoveGapColumns returns a profile without the columns that have a gap in every row.
+func RemoveGapColumns(profile []string) []string {
+	rows := make([][]byte, len(profile))
+	for col := 0; col < AlignmentLength(profile); col++ {
+		allGaps := true
+		for _, row := range profile {
+			if row[col] != '-' {
+				allGaps = false
+				break
+			}
+		}
+		if allGaps {
+			continue
+		}
+		for i, row := range profile {
+			rows[i] = append(rows[i], row[col])
+		}
+	}
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		result[i] = string(row)
+	}
+	return result
+}
+
+//RemoveGaps returns a gene without its gaps.
+func RemoveGaps(gene string) string {
+	seq := make([]byte, 0, len(gene))
+	for i := 0; i < len(gene); i++ {
+		if gene[i] != '-' {
+			seq = append(seq, gene[i])
+		}
+	}
+	return string(seq)
+}
@@ -0,0 +1,147 @@
+package align
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+//shiftRows returns an alignment of the genes where each gene starts after the given number of gaps and the rows
+//are padded with gaps at the end to the same length.
+func shiftRows(genes []string, shifts []int) []string {
+	length := 0
+	for i, gene := range genes {
+		if shifts[i]+len(gene) > length {
+			length = shifts[i] + len(gene)
+		}
+	}
+	msa := make([]string, len(genes))
+	for i, gene := range genes {
+		msa[i] = strings.Repeat("-", shifts[i]) + gene + strings.Repeat("-", length-shifts[i]-len(gene))
+	}
+	return msa
+}
+
+//checkRefinement fails the test unless refined has the rows of msa in the same order, with the same genes, and
+//rounds are the non-decreasing scores of refined, none below the score of msa.
+func checkRefinement(t *testing.T, msa, refined []string, rounds []int, scores *SubstitutionMatrix, gapOpen, gapExtend int) {
+	t.Helper()
+	if len(refined) != len(msa) {
+		t.Fatalf("%d rows after refinement, want %d", len(refined), len(msa))
+	}
+	for i := range msa {
+		if len(refined[i]) != len(refined[0]) {
+			t.Fatalf("rows of lengths %d and %d", len(refined[0]), len(refined[i]))
+		}
+		if RemoveGaps(refined[i]) != RemoveGaps(msa[i]) {
+			t.Fatalf("row %d is %s, not the gene %s", i, RemoveGaps(refined[i]), RemoveGaps(msa[i]))
+		}
+	}
+	previous := SumOfPairsScore(msa, scores, gapOpen, gapExtend)
+	for i, score := range rounds {
+		if score < previous {
+			t.Errorf("round %d scores %d, below %d", i+1, score, previous)
+		}
+		previous = score
+	}
+	if score := SumOfPairsScore(refined, scores, gapOpen, gapExtend); len(rounds) > 0 && score != rounds[len(rounds)-1] {
+		t.Errorf("refined alignment scores %d, the last round %d", score, rounds[len(rounds)-1])
+	}
+}
+
+func TestRefineAlignment(t *testing.T) {
+	scores := MatchMatrix(1, -1)
+	gapOpen, gapExtend := -3, -1
+
+	//the same gene shifted in every row: the first round lines the copies up and the second brings no improvement
+	genes := []string{"ACGTTGCAAC", "ACGTTGCAAC", "ACGTTGCAAC", "ACGTTGCAAC"}
+	msa := shiftRows(genes, []int{0, 3, 6, 1})
+	refined, rounds, err := RefineAlignment(msa, GuideNJ, scores, gapOpen, gapExtend, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRefinement(t, msa, refined, rounds, scores, gapOpen, gapExtend)
+	if perfect := SumOfPairsScore(genes, scores, gapOpen, gapExtend); len(rounds) != 2 || rounds[0] != perfect || rounds[1] != perfect {
+		t.Errorf("rounds score %v, want two rounds of %d", rounds, perfect)
+	}
+
+	rng := rand.New(rand.NewSource(5))
+	for i := 0; i < 30; i++ {
+		a, b := randomGenePair(30, rng)
+		c, d := randomGenePair(20, rng)
+		genes := []string{a, b, c + a[:10], d, b[5:]}
+		msa := shiftRows(genes, []int{rng.Intn(5), rng.Intn(5), rng.Intn(5), rng.Intn(5), rng.Intn(5)})
+		for _, guide := range []string{GuideNJ, GuideUPGMA} {
+			refined, rounds, err := RefineAlignment(msa, guide, scores, gapOpen, gapExtend, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkRefinement(t, msa, refined, rounds, scores, gapOpen, gapExtend)
+			//it stops after the first round that doesn't improve the score
+			if len(rounds) == 0 || len(rounds) == 100 {
+				t.Fatalf("%d refinement rounds", len(rounds))
+			}
+			last := SumOfPairsScore(msa, scores, gapOpen, gapExtend)
+			if len(rounds) > 1 {
+				last = rounds[len(rounds)-2]
+			}
+			if rounds[len(rounds)-1] != last {
+				t.Errorf("refinement stopped after improving from %d to %d", last, rounds[len(rounds)-1])
+			}
+		}
+	}
+}
+
+func TestRealignSplit(t *testing.T) {
+	scores := MatchMatrix(1, -1)
+	msa := shiftRows([]string{"ACGTAC", "TTACGT", "ACGGAC", "GTAC"}, []int{0, 2, 1, 3})
+	for _, group := range [][]int{{0}, {1, 3}, {0, 2, 3}} {
+		realigned := RealignSplit(msa, group, scores, -2, -1)
+		checkRefinement(t, msa, realigned, nil, scores, -2, -1)
+	}
+}
+
+func TestGuideEdges(t *testing.T) {
+	//the rooted guide tree has 2n-2 edges, and the two below the root split the genes the same way
+	dist := [][]float64{
+		{0, 17, 21, 31, 23},
+		{17, 0, 30, 34, 21},
+		{21, 30, 0, 28, 39},
+		{31, 34, 28, 0, 43},
+		{23, 21, 39, 43, 0},
+	}
+	root, err := GuideTree(dist, GuideUPGMA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges := GuideEdges(root)
+	if len(edges) != 2*len(dist)-3 {
+		t.Errorf("%d edges, want %d", len(edges), 2*len(dist)-3)
+	}
+	splits := make(map[string]bool)
+	for _, node := range edges {
+		//write each split with the side that doesn't have gene 0
+		inGroup := make(map[int]bool)
+		for _, i := range node.Leaves() {
+			inGroup[i] = true
+		}
+		side := []int{}
+		for i := range dist {
+			if inGroup[i] != inGroup[0] {
+				side = append(side, i)
+			}
+		}
+		sort.Ints(side)
+		key := fmt.Sprint(side)
+		if splits[key] {
+			t.Errorf("split %s appears twice", key)
+		}
+		splits[key] = true
+	}
+
+	if edges := GuideEdges(&GuideNode{Leaf: 0}); len(edges) != 0 {
+		t.Errorf("a single gene has %d edges", len(edges))
+	}
+}
@@ -0,0 +1,331 @@
+package align
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//Substitution matrices
+//A substitution matrix gives the score of aligning each pair of letters. The built-in protein matrices are
+//BLOSUM62, BLOSUM45 and PAM250; for DNA, MatchMatrix gives one reward and one penalty and
+//TransitionTransversionMatrix scores transitions (A<->G, C<->T) higher than transversions.
+//Custom matrices are read from the NCBI text format: '#' comment lines, a header line with the column letters,
+//then one line per row starting with the row letter.
+
+//SubstitutionMatrix holds the score of every pair of letters, in upper and lower case.
+type SubstitutionMatrix struct {
+	Name    string
+	Letters string //letters of a matrix read in the NCBI format, empty for a matrix that scores any letter
+	scores  [256][256]int32
+}
+
+//Score returns the score of aligning letter a with letter b.
+func (sm *SubstitutionMatrix) Score(a, b byte) int {
+	return int(sm.scores[a][b])
+}
+
+//set stores the score of a pair of letters for both cases of each letter.
+func (sm *SubstitutionMatrix) set(a, b byte, score int) {
+	for _, x := range []byte{Upper(a), Lower(a)} {
+		for _, y := range []byte{Upper(b), Lower(b)} {
+			sm.scores[x][y] = int32(score)
+		}
+	}
+}
+
+//Upper returns the upper case of a letter.
+func Upper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
+
+//Lower returns the lower case of a letter.
+func Lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
+
+//MatchMatrix returns the matrix that scores identical letters with reward and different letters with penalty.
+func MatchMatrix(reward, penalty int) *SubstitutionMatrix {
+	sm := &SubstitutionMatrix{Name: "match " + strconv.Itoa(reward) + "/" + strconv.Itoa(penalty)}
+	for a := range sm.scores {
+		for b := range sm.scores[a] {
+			sm.scores[a][b] = int32(penalty)
+		}
+		sm.set(byte(a), byte(a), reward)
+	}
+	return sm
+}
+
+//TransitionTransversionMatrix returns a DNA matrix that scores identical nucleotides with match, purine-purine and
+//pyrimidine-pyrimidine substitutions with transition, and other substitutions with transversion.
+//U is scored as T, and pairs with any other letter are scored as transversions.
+func TransitionTransversionMatrix(match, transition, transversion int) *SubstitutionMatrix {
+	sm := &SubstitutionMatrix{Name: "DNA " + strconv.Itoa(match) + "/" + strconv.Itoa(transition) + "/" + strconv.Itoa(transversion)}
+	for a := range sm.scores {
+		for b := range sm.scores[a] {
+			sm.scores[a][b] = int32(transversion)
+		}
+	}
+	purine := map[byte]bool{'A': true, 'G': true}
+	nucs := "ACGTU"
+	for i := range nucs {
+		for j := range nucs {
+			a, b := nucs[i], nucs[j]
+			if a == 'U' {
+				a = 'T'
+			}
+			if b == 'U' {
+				b = 'T'
+			}
+			if a == b {
+				sm.set(nucs[i], nucs[j], match)
+			} else if purine[a] == purine[b] {
+				sm.set(nucs[i], nucs[j], transition)
+			}
+		}
+	}
+	return sm
+}
+
+//ReadSubstitutionMatrixFromFile reads a substitution matrix in the NCBI text format from a file.
+func ReadSubstitutionMatrixFromFile(filename string) (*SubstitutionMatrix, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	sm, err := ParseSubstitutionMatrix(filename, file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return sm, nil
+}
+
+//ParseSubstitutionMatrix reads a substitution matrix in the NCBI text format from r and names it name.
+//Every row letter must be one of the header letters and every row must have one score per header letter.
+//Pairs of letters that are not in the matrix score the lowest score of the matrix.
+func ParseSubstitutionMatrix(name string, r io.Reader) (*SubstitutionMatrix, error) {
+	sm := &SubstitutionMatrix{Name: name}
+	var header []byte
+	rows := make(map[byte]bool)
+	lowest := 0
+	haveScore := false
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if header == nil {
+			for _, field := range fields {
+				if len(field) != 1 {
+					return nil, fmt.Errorf("line %d: %q is not a single letter", lineNum, field)
+				}
+				header = append(header, Upper(field[0]))
+			}
+			continue
+		}
+		if len(fields[0]) != 1 {
+			return nil, fmt.Errorf("line %d: %q is not a single letter", lineNum, fields[0])
+		}
+		row := Upper(fields[0][0])
+		if strings.IndexByte(string(header), row) < 0 {
+			return nil, fmt.Errorf("line %d: row %c is not in the header", lineNum, row)
+		}
+		if rows[row] {
+			return nil, fmt.Errorf("line %d: row %c appears twice", lineNum, row)
+		}
+		rows[row] = true
+		if len(fields)-1 != len(header) {
+			return nil, fmt.Errorf("line %d: row %c has %d scores, want %d", lineNum, row, len(fields)-1, len(header))
+		}
+		for i, field := range fields[1:] {
+			score, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: wrong score %q", lineNum, field)
+			}
+			if !haveScore || score < lowest {
+				lowest = score
+				haveScore = true
+			}
+			sm.set(row, header[i], score)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if header == nil || len(rows) == 0 {
+		return nil, fmt.Errorf("no substitution matrix")
+	}
+	for _, letter := range header {
+		if !rows[letter] {
+			return nil, fmt.Errorf("no row for %c", letter)
+		}
+	}
+	sm.Letters = string(header)
+
+	//pairs with a letter outside the matrix get the lowest score
+	known := make(map[byte]bool, 2*len(header))
+	for _, letter := range header {
+		known[Upper(letter)] = true
+		known[Lower(letter)] = true
+	}
+	for a := range sm.scores {
+		for b := range sm.scores[a] {
+			if !known[byte(a)] || !known[byte(b)] {
+				sm.scores[a][b] = int32(lowest)
+			}
+		}
+	}
+	return sm, nil
+}
+
+//MustParseSubstitutionMatrix is like ParseSubstitutionMatrix but panics on error. It is used for the built-in matrices.
+func MustParseSubstitutionMatrix(name, text string) *SubstitutionMatrix {
+	sm, err := ParseSubstitutionMatrix(name, strings.NewReader(text))
+	if err != nil {
+		panic("substitution matrix " + name + ": " + err.Error())
+	}
+	return sm
+}
+
+//SubstitutionMatrixByName returns the built-in matrix with the given name (blosum62, blosum45, pam250 or dna),
+//or reads the matrix from the file with that name.
+func SubstitutionMatrixByName(name string) (*SubstitutionMatrix, error) {
+	switch strings.ToLower(name) {
+	case "blosum62":
+		return BLOSUM62, nil
+	case "blosum45":
+		return BLOSUM45, nil
+	case "pam250":
+		return PAM250, nil
+	case "dna":
+		return DNATransitionTransversion, nil
+	}
+	return ReadSubstitutionMatrixFromFile(name)
+}
+
+//MatrixAlphabet returns the alphabet of the sequences scored by a matrix: Protein if the matrix has letters
+//that are not nucleotide codes, RNA if it has U but no T, DNA otherwise.
+func MatrixAlphabet(sm *SubstitutionMatrix) Alphabet {
+	for i := 0; i < len(sm.Letters); i++ {
+		if !DNA.Contains(sm.Letters[i]) && !RNA.Contains(sm.Letters[i]) {
+			return Protein
+		}
+	}
+	if strings.IndexByte(sm.Letters, 'U') >= 0 && strings.IndexByte(sm.Letters, 'T') < 0 {
+		return RNA
+	}
+	return DNA
+}
+
+//DNATransitionTransversion scores a match 2, a transition -1 and a transversion -2.
+var DNATransitionTransversion = TransitionTransversionMatrix(2, -1, -2)
+
+//BLOSUM62, BLOSUM45 and PAM250 are the NCBI protein matrices.
+var (
+	BLOSUM62 = MustParseSubstitutionMatrix("BLOSUM62", blosum62Text)
+	BLOSUM45 = MustParseSubstitutionMatrix("BLOSUM45", blosum45Text)
+	PAM250   = MustParseSubstitutionMatrix("PAM250", pam250Text)
+)
+
+const blosum62Text = `
+#  Matrix made by matblas from blosum62.iij
+#  BLOSUM Clustered Scoring Matrix in 1/2 Bit Units
+   A  R  N  D  C  Q  E  G  H  I  L  K  M  F  P  S  T  W  Y  V  B  Z  X  *
+A  4 -1 -2 -2  0 -1 -1  0 -2 -1 -1 -1 -1 -2 -1  1  0 -3 -2  0 -2 -1  0 -4
+R -1  5  0 -2 -3  1  0 -2  0 -3 -2  2 -1 -3 -2 -1 -1 -3 -2 -3 -1  0 -1 -4
+N -2  0  6  1 -3  0  0  0  1 -3 -3  0 -2 -3 -2  1  0 -4 -2 -3  3  0 -1 -4
+D -2 -2  1  6 -3  0  2 -1 -1 -3 -4 -1 -3 -3 -1  0 -1 -4 -3 -3  4  1 -1 -4
+C  0 -3 -3 -3  9 -3 -4 -3 -3 -1 -1 -3 -1 -2 -3 -1 -1 -2 -2 -1 -3 -3 -2 -4
+Q -1  1  0  0 -3  5  2 -2  0 -3 -2  1  0 -3 -1  0 -1 -2 -1 -2  0  3 -1 -4
+E -1  0  0  2 -4  2  5 -2  0 -3 -3  1 -2 -3 -1  0 -1 -3 -2 -2  1  4 -1 -4
+G  0 -2  0 -1 -3 -2 -2  6 -2 -4 -4 -2 -3 -3 -2  0 -2 -2 -3 -3 -1 -2 -1 -4
+H -2  0  1 -1 -3  0  0 -2  8 -3 -3 -1 -2 -1 -2 -1 -2 -2  2 -3  0  0 -1 -4
+I -1 -3 -3 -3 -1 -3 -3 -4 -3  4  2 -3  1  0 -3 -2 -1 -3 -1  3 -3 -3 -1 -4
+L -1 -2 -3 -4 -1 -2 -3 -4 -3  2  4 -2  2  0 -3 -2 -1 -2 -1  1 -4 -3 -1 -4
+K -1  2  0 -1 -3  1  1 -2 -1 -3 -2  5 -1 -3 -1  0 -1 -3 -2 -2  0  1 -1 -4
+M -1 -1 -2 -3 -1  0 -2 -3 -2  1  2 -1  5  0 -2 -1 -1 -1 -1  1 -3 -1 -1 -4
+F -2 -3 -3 -3 -2 -3 -3 -3 -1  0  0 -3  0  6 -4 -2 -2  1  3 -1 -3 -3 -1 -4
+P -1 -2 -2 -1 -3 -1 -1 -2 -2 -3 -3 -1 -2 -4  7 -1 -1 -4 -3 -2 -2 -1 -2 -4
+S  1 -1  1  0 -1  0  0  0 -1 -2 -2  0 -1 -2 -1  4  1 -3 -2 -2  0  0  0 -4
+T  0 -1  0 -1 -1 -1 -1 -2 -2 -1 -1 -1 -1 -2 -1  1  5 -2 -2  0 -1 -1  0 -4
+W -3 -3 -4 -4 -2 -2 -3 -2 -2 -3 -2 -3 -1  1 -4 -3 -2 11  2 -3 -4 -3 -2 -4
+Y -2 -2 -2 -3 -2 -1 -2 -3  2 -1 -1 -2 -1  3 -3 -2 -2  2  7 -1 -3 -2 -1 -4
+V  0 -3 -3 -3 -1 -2 -2 -3 -3  3  1 -2  1 -1 -2 -2  0 -3 -1  4 -3 -2 -1 -4
+B -2 -1  3  4 -3  0  1 -1  0 -3 -4  0 -3 -3 -2  0 -1 -4 -3 -3  4  1 -1 -4
+Z -1  0  0  1 -3  3  4 -2  0 -3 -3  1 -1 -3 -1  0 -1 -3 -2 -2  1  4 -1 -4
+X  0 -1 -1 -1 -2 -1 -1 -1 -1 -1 -1 -1 -1 -1 -2  0  0 -2 -1 -1 -1 -1 -1 -4
+* -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4 -4  1
+`
+
+const blosum45Text = `
+#  Matrix made by matblas from blosum45.iij
+#  BLOSUM Clustered Scoring Matrix in 1/3 Bit Units
+   A  R  N  D  C  Q  E  G  H  I  L  K  M  F  P  S  T  W  Y  V  B  Z  X  *
+A  5 -2 -1 -2 -1 -1 -1  0 -2 -1 -1 -1 -1 -2 -1  1  0 -2 -2  0 -1 -1  0 -5
+R -2  7  0 -1 -3  1  0 -2  0 -3 -2  3 -1 -2 -2 -1 -1 -2 -1 -2 -1  0 -1 -5
+N -1  0  6  2 -2  0  0  0  1 -2 -3  0 -2 -2 -2  1  0 -4 -2 -3  4  0 -1 -5
+D -2 -1  2  7 -3  0  2 -1  0 -4 -3  0 -3 -4 -1  0 -1 -4 -2 -3  5  1 -1 -5
+C -1 -3 -2 -3 12 -3 -3 -3 -3 -3 -2 -3 -2 -2 -4 -1 -1 -5 -3 -1 -2 -3 -2 -5
+Q -1  1  0  0 -3  6  2 -2  1 -2 -2  1  0 -4 -1  0 -1 -2 -1 -3  0  4 -1 -5
+E -1  0  0  2 -3  2  6 -2  0 -3 -2  1 -2 -3  0  0 -1 -3 -2 -3  1  4 -1 -5
+G  0 -2  0 -1 -3 -2 -2  7 -2 -4 -3 -2 -2 -3 -2  0 -2 -2 -3 -3 -1 -2 -1 -5
+H -2  0  1  0 -3  1  0 -2 10 -3 -2 -1  0 -2 -2 -1 -2 -3  2 -3  0  0 -1 -5
+I -1 -3 -2 -4 -3 -2 -3 -4 -3  5  2 -3  2  0 -2 -2 -1 -2  0  3 -3 -3 -1 -5
+L -1 -2 -3 -3 -2 -2 -2 -3 -2  2  5 -3  2  1 -3 -3 -1 -2  0  1 -3 -2 -1 -5
+K -1  3  0  0 -3  1  1 -2 -1 -3 -3  5 -1 -3 -1 -1 -1 -2 -1 -2  0  1 -1 -5
+M -1 -1 -2 -3 -2  0 -2 -2  0  2  2 -1  6  0 -2 -2 -1 -2  0  1 -2 -1 -1 -5
+F -2 -2 -2 -4 -2 -4 -3 -3 -2  0  1 -3  0  8 -3 -2 -1  1  3  0 -3 -3 -1 -5
+P -1 -2 -2 -1 -4 -1  0 -2 -2 -2 -3 -1 -2 -3  9 -1 -1 -3 -3 -3 -2 -1 -1 -5
+S  1 -1  1  0 -1  0  0  0 -1 -2 -3 -1 -2 -2 -1  4  2 -4 -2 -1  0  0  0 -5
+T  0 -1  0 -1 -1 -1 -1 -2 -2 -1 -1 -1 -1 -1 -1  2  5 -3 -1  0  0 -1  0 -5
+W -2 -2 -4 -4 -5 -2 -3 -2 -3 -2 -2 -2 -2  1 -3 -4 -3 15  3 -3 -4 -2 -2 -5
+Y -2 -1 -2 -2 -3 -1 -2 -3  2  0  0 -1  0  3 -3 -2 -1  3  8 -1 -2 -2 -1 -5
+V  0 -2 -3 -3 -1 -3 -3 -3 -3  3  1 -2  1  0 -3 -1  0 -3 -1  5 -3 -3 -1 -5
+B -1 -1  4  5 -2  0  1 -1  0 -3 -3  0 -2 -3 -2  0  0 -4 -2 -3  4  2 -1 -5
+Z -1  0  0  1 -3  4  4 -2  0 -3 -2  1 -1 -3 -1  0 -1 -2 -2 -3  2  4 -1 -5
+X  0 -1 -1 -1 -2 -1 -1 -1 -1 -1 -1 -1 -1 -1 -1  0  0 -2 -1 -1 -1 -1 -1 -5
+* -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5 -5  1
+`
+
+const pam250Text = `
+#  PAM 250 substitution matrix
+   A  R  N  D  C  Q  E  G  H  I  L  K  M  F  P  S  T  W  Y  V  B  Z  X  *
+A  2 -2  0  0 -2  0  0  1 -1 -1 -2 -1 -1 -3  1  1  1 -6 -3  0  0  0  0 -8
+R -2  6  0 -1 -4  1 -1 -3  2 -2 -3  3  0 -4  0  0 -1  2 -4 -2 -1  0 -1 -8
+N  0  0  2  2 -4  1  1  0  2 -2 -3  1 -2 -3  0  1  0 -4 -2 -2  2  1  0 -8
+D  0 -1  2  4 -5  2  3  1  1 -2 -4  0 -3 -6 -1  0  0 -7 -4 -2  3  3 -1 -8
+C -2 -4 -4 -5 12 -5 -5 -3 -3 -2 -6 -5 -5 -4 -3  0 -2 -8  0 -2 -4 -5 -3 -8
+Q  0  1  1  2 -5  4  2 -1  3 -2 -2  1 -1 -5  0 -1 -1 -5 -4 -2  1  3 -1 -8
+E  0 -1  1  3 -5  2  4  0  1 -2 -3  0 -2 -5 -1  0  0 -7 -4 -2  3  3 -1 -8
+G  1 -3  0  1 -3 -1  0  5 -2 -3 -4 -2 -3 -5  0  1  0 -7 -5 -1  0  0 -1 -8
+H -1  2  2  1 -3  3  1 -2  6 -2 -2  0 -2 -2  0 -1 -1 -3  0 -2  1  2 -1 -8
+I -1 -2 -2 -2 -2 -2 -2 -3 -2  5  2 -2  2  1 -2 -1  0 -5 -1  4 -2 -2 -1 -8
+L -2 -3 -3 -4 -6 -2 -3 -4 -2  2  6 -3  4  2 -3 -3 -2 -2 -1  2 -3 -3 -1 -8
+K -1  3  1  0 -5  1  0 -2  0 -2 -3  5  0 -5 -1  0  0 -3 -4 -2  1  0 -1 -8
+M -1  0 -2 -3 -5 -1 -2 -3 -2  2  4  0  6  0 -2 -2 -1 -4 -2  2 -2 -2 -1 -8
+F -3 -4 -3 -6 -4 -5 -5 -5 -2  1  2 -5  0  9 -5 -3 -3  0  7 -1 -4 -5 -2 -8
+P  1  0  0 -1 -3  0 -1  0  0 -2 -3 -1 -2 -5  6  1  0 -6 -5 -1 -1  0 -1 -8
+S  1  0  1  0  0 -1  0  1 -1 -1 -3  0 -2 -3  1  2  1 -2 -3 -1  0  0  0 -8
+T  1 -1  0  0 -2 -1  0  0 -1  0 -2  0 -1 -3  0  1  3 -5 -3  0  0 -1  0 -8
+W -6  2 -4 -7 -8 -5 -7 -7 -3 -5 -2 -3 -4  0 -6 -2 -5 17  0 -6 -5 -6 -4 -8
+Y -3 -4 -2 -4  0 -4 -4 -5  0 -1 -1 -4 -2  7 -5 -3 -3  0 10 -2 -3 -4 -2 -8
+V  0 -2 -2 -2 -2 -2 -2 -1 -2  4  2 -2  2 -1 -1 -1  0 -6 -2  4 -2 -2 -1 -8
+B  0 -1  2  3 -4  1  3  0  1 -2 -3  1 -2 -4 -1  0  0 -5 -3 -2  3  2 -1 -8
+Z  0  0  1  3 -5  3  3  0  2 -2 -3  0 -2 -5  0  0 -1 -6 -4 -2  2  3 -1 -8
+X  0 -1  0 -1 -3 -1 -1 -1 -1 -1 -1 -1 -1 -2 -1  0  0 -4 -2 -1 -1 -1 -1 -8
+* -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8 -8  1
+`
@@ -0,0 +1,70 @@
+package align
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltinMatrices(t *testing.T) {
+	tests := []struct {
+		sm    *SubstitutionMatrix
+		a, b  byte
+		score int
+	}{
+		{BLOSUM62, 'W', 'W', 11},
+		{BLOSUM62, 'C', 'C', 9},
+		{BLOSUM62, 'A', 'R', -1},
+		{BLOSUM62, 'w', 'W', 11}, //letters of either case
+		{BLOSUM62, 'r', 'a', -1},
+		{BLOSUM62, 'J', 'A', -4}, //letters outside the matrix get its lowest score
+		{BLOSUM62, 'a', '-', -4},
+		{PAM250, 'W', 'W', 17},
+		{PAM250, 'W', 'C', -8},
+		{PAM250, 'O', 'O', -8},
+		{DNATransitionTransversion, 'A', 'A', 2},
+		{DNATransitionTransversion, 'A', 'G', -1}, //transitions
+		{DNATransitionTransversion, 'c', 'T', -1},
+		{DNATransitionTransversion, 'C', 'U', -1},
+		{DNATransitionTransversion, 'A', 'C', -2}, //transversions
+		{DNATransitionTransversion, 'G', 't', -2},
+		{DNATransitionTransversion, 'U', 'T', 2},
+		{DNATransitionTransversion, 'A', 'N', -2},
+	}
+	for _, tc := range tests {
+		if got := tc.sm.Score(tc.a, tc.b); got != tc.score {
+			t.Errorf("%s scores %c/%c %d, want %d", tc.sm.Name, tc.a, tc.b, got, tc.score)
+		}
+		if got := tc.sm.Score(tc.b, tc.a); got != tc.score {
+			t.Errorf("%s scores %c/%c %d, want %d", tc.sm.Name, tc.b, tc.a, got, tc.score)
+		}
+	}
+}
+
+func TestParseSubstitutionMatrix(t *testing.T) {
+	sm, err := ParseSubstitutionMatrix("small", strings.NewReader("# a comment\n\n   A  c\nA  3 -2\nC -2  5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sm.Letters != "AC" {
+		t.Errorf("letters %q, want AC", sm.Letters)
+	}
+	if sm.Score('a', 'C') != -2 || sm.Score('c', 'c') != 5 || sm.Score('G', 'A') != -2 {
+		t.Errorf("scores A/C %d, C/C %d, G/A %d, want -2, 5, -2", sm.Score('a', 'C'), sm.Score('c', 'c'), sm.Score('G', 'A'))
+	}
+
+	for _, bad := range []string{
+		"",                             //empty input
+		"# only a comment\n",           //no header
+		"   A  C\n",                    //no rows
+		"   A  C\nA  3 -2\nC -2\n",     //short row
+		"   A  C\nA  3 -2\n",           //missing row
+		"   A  C\nA  3 -2\nC -2  x\n",  //bad integer
+		"   A  C\nA  3 -2\nG -2  5\n",  //row not in the header
+		"   A  C\nA  3 -2\nA  3 -2\n",  //row twice
+		"   A  CG\nA  3 -2\nC -2  5\n", //header field longer than a letter
+	} {
+		if _, err := ParseSubstitutionMatrix("bad", strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseSubstitutionMatrix accepted %q", bad)
+		}
+	}
+}
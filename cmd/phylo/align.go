@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/align"
+)
+
+// Multiple sequence alignment
+//   phylo align [options] genes.fasta ...
+// The genes of the FASTA files are aligned and the alignment is written to -out; phylo distance turns it into a
+// distance matrix.
+
+// AlignFlags are the flags that choose how genes are aligned, shared by the align and pipeline commands.
+type AlignFlags struct {
+	reward, penalty, gapOpen, gapExtend, refine *int
+	matrixName, mode, method, guide, alphabet   *string
+}
+
+// AddAlignFlags defines the alignment flags on a flag set.
+func AddAlignFlags(flags *flag.FlagSet) *AlignFlags {
+	f := &AlignFlags{}
+	//a gap of length k scores gapopen + k*gap; with -gapopen 0 gaps are linear
+	//-matrix is blosum62, blosum45, pam250, dna (transition/transversion) or a matrix file in the NCBI format;
+	//without it a match scores the -reward value and a mismatch the -penalty value
+	f.reward = flags.Int("reward", 1, "score of a match")
+	f.penalty = flags.Int("penalty", -1, "score of a mismatch")
+	f.matrixName = flags.String("matrix", "", "substitution matrix")
+	//without -alphabet, genes are read as protein with a protein matrix, as RNA with a matrix of U but not T,
+	//and as DNA otherwise
+	f.alphabet = flags.String("alphabet", "", "alphabet of the genes: dna, rna or protein (default from the matrix)")
+	//-mode local or semiglobal does not score the unaligned ends of the genes
+	f.mode = flags.String("mode", align.Global, "pairwise alignment mode: global, local or semiglobal")
+	//-msa progressive aligns the genes up a guide tree built with -guide nj or upgma instead of the star method
+	f.method = flags.String("msa", align.Star, "multiple alignment method: star or progressive")
+	f.guide = flags.String("guide", align.GuideNJ, "guide tree of the progressive alignment: nj or upgma")
+	//-refine n realigns the two groups of genes on each side of every guide tree edge for at most n rounds
+	f.refine = flags.Int("refine", 0, "maximum number of refinement rounds of the multiple alignment")
+	f.gapOpen = flags.Int("gapopen", 0, "score for opening a gap")
+	f.gapExtend = flags.Int("gap", -2, "score for each position of a gap")
+	return f
+}
+
+// Options returns the alignment options given by the flags.
+func (f *AlignFlags) Options() (align.Options, error) {
+	opts := align.Options{
+		Mode:      *f.mode,
+		Method:    *f.method,
+		Guide:     *f.guide,
+		Scores:    align.MatchMatrix(*f.reward, *f.penalty),
+		GapOpen:   *f.gapOpen,
+		GapExtend: *f.gapExtend,
+		Refine:    *f.refine,
+	}
+	if *f.matrixName != "" {
+		var err error
+		opts.Scores, err = align.SubstitutionMatrixByName(*f.matrixName)
+		if err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// Alphabet returns the alphabet the genes are read in: the -alphabet flag, or the alphabet of the scores.
+func (f *AlignFlags) Alphabet(scores *align.SubstitutionMatrix) (align.Alphabet, error) {
+	if *f.alphabet == "" {
+		return align.MatrixAlphabet(scores), nil
+	}
+	return align.AlphabetByName(*f.alphabet)
+}
+
+// ReadGenes reads the genes of FASTA files and names them with align.RecordNames.
+func ReadGenes(fileNames []string, alphabet align.Alphabet) ([]string, []string, error) {
+	records, err := align.ReadFastaFiles(fileNames, alphabet)
+	if err != nil {
+		return nil, nil, err
+	}
+	//a gene that is alone in its file is named after the file, other genes after their FASTA ID
+	speciesName, err := align.RecordNames(records)
+	if err != nil {
+		return nil, nil, err
+	}
+	genes := make([]string, len(records))
+	for i, rec := range records {
+		genes[i] = rec.Sequence
+	}
+	return speciesName, genes, nil
+}
+
+// RunAlign aligns the genes of the FASTA files given in args and writes the multiple alignment.
+func RunAlign(args []string) error {
+	flags := flag.NewFlagSet("align", flag.ExitOnError)
+	alignFlags := AddAlignFlags(flags)
+	//-format chooses how the alignment is written: fasta, phylip, phylip-sequential, clustal or stockholm
+	format := flags.String("format", "fasta", "output format of the multiple sequence alignment")
+	out := flags.String("out", "", "output file of the multiple sequence alignment (default alignment with the format's extension)")
+	//-pairwise prints the alignment of the first gene with each other gene, with its coordinates and score,
+	//instead of aligning all of them
+	pairwise := flags.Bool("pairwise", false, "print the pairwise alignments with the first gene and exit")
+	//-codon aligns the translated CDS of each mRNA (-cds file, or the longest ORF) and writes the in-frame codon alignment
+	codon := flags.Bool("codon", false, "align the coding sequences codon by codon")
+	cdsFile := flags.String("cds", "", "file of name<TAB>start<TAB>end CDS coordinates for -codon")
+	flags.Parse(args)
+	if _, ok := align.AlignmentFormats[*format]; !ok {
+		return fmt.Errorf("unknown alignment format %s", *format)
+	}
+	if *out == "" {
+		*out = "alignment" + align.AlignmentFormats[*format]
+	}
+	opts, err := alignFlags.Options()
+	if err != nil {
+		return err
+	}
+
+	//protein matrices read the genes as protein sequences; codon alignment reads mRNAs and aligns their proteins
+	alphabet, err := alignFlags.Alphabet(opts.Scores)
+	if err != nil {
+		return err
+	}
+	if *codon {
+		if align.MatrixAlphabet(opts.Scores).Name != align.Protein.Name {
+			opts.Scores = align.BLOSUM62
+		}
+		if alphabet.Name != align.RNA.Name {
+			alphabet = align.DNA
+		}
+	}
+	speciesName, genes, err := ReadGenes(flags.Args(), alphabet)
+	if err != nil {
+		return err
+	}
+	fmt.Println("We have read genes from file.")
+	var cds []align.CDS
+	mrnas := genes
+	if *codon {
+		given := map[string]align.CDS{}
+		if *cdsFile != "" {
+			given, err = align.ReadCDSFromFile(*cdsFile)
+			if err != nil {
+				return err
+			}
+		}
+		cds, err = align.FindCDS(speciesName, genes, given)
+		if err != nil {
+			return err
+		}
+		genes = make([]string, len(mrnas))
+		for i, c := range cds {
+			fmt.Println("CDS of", speciesName[i]+":", c.Start+1, "-", c.End)
+			genes[i] = align.Translate(mrnas[i][c.Start:c.End])
+		}
+	}
+	if *pairwise {
+		for i := 1; i < len(genes); i++ {
+			align.PrintPairwiseAlignment(speciesName[0], speciesName[i], align.AlignPair(genes[0], genes[i], opts.Mode, opts.Scores, opts.GapOpen, opts.GapExtend))
+		}
+		return nil
+	}
+
+	msa, rounds, err := align.Align(genes, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Multiple Sequence Alignment have succeeded!")
+	for i, score := range rounds {
+		fmt.Println("Refinement round", i+1, "sum-of-pairs score:", score)
+	}
+	fmt.Println("Sum-of-pairs score:", align.SumOfPairsScore(msa, opts.Scores, opts.GapOpen, opts.GapExtend))
+	if *codon {
+		msa = align.CodonAlignment(msa, mrnas, cds)
+	}
+	if err := align.WriteAlignmentToFile(speciesName, msa, *format, *out); err != nil {
+		return fmt.Errorf("couldn't write the alignment to file: %w", err)
+	}
+	fmt.Println("We have finished writing the alignment to", *out)
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/distance"
+)
+
+// Distance matrix
+//   phylo distance [-model count|p|jc|k2p|tn93|logdet] [-gaps pairwise|complete|mismatch] [-out distanceMatrix.txt] alignment.fasta
+// The default count model with gaps as mismatches gives the mismatch counts of the distance matrices in data/.
+
+// RunDistance reads the aligned FASTA file given in args and writes the distance matrix of its sequences.
+func RunDistance(args []string) error {
+	flags := flag.NewFlagSet("distance", flag.ExitOnError)
+	model := flags.String("model", distance.Count, "distance model: count, p, jc, k2p, tn93 or logdet")
+	gaps := flags.String("gaps", "", "gap handling: pairwise, complete or mismatch (default mismatch for count, pairwise otherwise)")
+	out := flags.String("out", "distanceMatrix.txt", "output distance matrix file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: phylo distance [options] alignment.fasta")
+	}
+
+	speciesName, msa, err := distance.ReadAlignmentFromFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	dmatrix, err := distance.EvolutionaryDistanceMatrix(speciesName, msa, *model, *gaps)
+	if err != nil {
+		return err
+	}
+	fmt.Println(dmatrix)
+	if err := distance.WriteMatrixToFile(speciesName, dmatrix, *out); err != nil {
+		return fmt.Errorf("couldn't write the distance matrix to file: %w", err)
+	}
+	fmt.Println("We have finishing writing the distance matrix to file!")
+	return nil
+}
@@ -0,0 +1,61 @@
+// Command phylo runs the phylogenetics programs of this repository as subcommands:
+//
+//	phylo align      align the genes of FASTA files
+//	phylo distance   build a distance matrix from an aligned FASTA file
+//	phylo nj         build a neighbor-joining, BIONJ, UPGMA or WPGMA tree from a distance matrix (or bootstrap an alignment)
+//	phylo parsimony  label the internal nodes of a tree with the most parsimonious sequences
+//	phylo root       root a tree by outgroup, midpoint, minimum variance or reconciliation
+//	phylo reconcile  reconcile a gene tree with a species tree
+//	phylo pipeline   go from genes to a rooted, reconciled gene tree, one stage at a time
+//	phylo consensus  build the consensus of a set of trees
+//	phylo treedist   compute the distances between trees
+//
+// Run "phylo <command> -h" for the options of a command.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// commands maps each subcommand to the function that runs it with the arguments after its name.
+var commands = map[string]func(args []string) error{
+	"align":     RunAlign,
+	"distance":  RunDistance,
+	"nj":        RunNJ,
+	"parsimony": RunParsimony,
+	"root":      RunRoot,
+	"reconcile": RunReconcile,
+	"pipeline":  RunPipeline,
+	"consensus": RunConsensus,
+	"treedist":  RunTreeDistance,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		Usage()
+		os.Exit(1)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Println("Error: unknown command", os.Args[1])
+		Usage()
+		os.Exit(1)
+	}
+	if err := run(os.Args[2:]); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+// Usage prints the list of subcommands.
+func Usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("Usage: phylo <command> [options] [files]")
+	fmt.Println("Commands:", names)
+}
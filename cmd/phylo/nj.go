@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/distance"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/nj"
+)
+
+// Neighbor-joining
+//   phylo nj [-method nj|fast|bionj|upgma|wpgma] [-out tree.nwk] distanceMatrix.txt
+//   phylo nj -bootstrap n [-model count] [-gaps mismatch] [-threads n] [-seed 1] [-replicates file] [-out tree.nwk] alignment.fasta
+// The fast method builds the neighbor-joining tree with a bounded search for the pair to join, for thousands of
+// taxa. The bionj method weights the distances to each new node by their variances. The upgma and wpgma methods
+// build rooted ultrametric trees and print the height of each internal node.
+
+// Tree building methods
+const (
+	NJ     = "nj"
+	FastNJ = "fast"
+	BIONJ  = "bionj"
+	UPGMA  = "upgma"
+	WPGMA  = "wpgma"
+)
+
+// RunNJ builds the neighbor-joining tree of the distance matrix, or of the aligned FASTA file with bootstrap
+// support, given in args and writes it in Newick format.
+func RunNJ(args []string) error {
+	flags := flag.NewFlagSet("nj", flag.ExitOnError)
+	method := flags.String("method", NJ, "tree building method: nj, fast, bionj, upgma or wpgma")
+	//-bootstrap n reads an aligned FASTA file instead of a distance matrix and writes the tree with the support
+	//of each branch from n bootstrap replicates, built in parallel by -threads goroutines
+	replicates := flags.Int("bootstrap", 0, "number of bootstrap replicates (input is then an aligned FASTA file)")
+	threads := flags.Int("threads", runtime.NumCPU(), "number of goroutines building bootstrap replicates")
+	seed := flags.Int64("seed", 1, "random seed of the bootstrap")
+	//the distances of the alignment and of its replicates, as in phylo distance
+	model := flags.String("model", distance.Count, "distance model of the bootstrap: count, p, jc, k2p, tn93 or logdet")
+	gaps := flags.String("gaps", "", "gap handling of the bootstrap: pairwise, complete or mismatch (default mismatch for count, pairwise otherwise)")
+	replicateFile := flags.String("replicates", "", "file to write the bootstrap replicate trees to, one per line")
+	out := flags.String("out", "tree.nwk", "output Newick file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: phylo nj [options] distanceMatrix.txt")
+	}
+	if *method != NJ && *method != FastNJ && *method != BIONJ && *method != UPGMA && *method != WPGMA {
+		return fmt.Errorf("unknown tree building method %s", *method)
+	}
+	if *replicates > 0 && *method != NJ {
+		return fmt.Errorf("bootstrap trees are built with nj, not %s", *method)
+	}
+	fmt.Println("Start construct the tree using the", *method, "method!")
+
+	if *replicates > 0 {
+		speciesName, msa, err := distance.ReadAlignmentFromFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		t, support, trees, err := nj.Bootstrap(msa, speciesName, *model, *gaps, *replicates, *threads, *seed)
+		if err != nil {
+			return err
+		}
+		newick := t.NewickWithSupport(support)
+		fmt.Println(newick)
+		if err := WriteLines(*out, newick); err != nil {
+			return fmt.Errorf("couldn't write the tree to file: %w", err)
+		}
+		//the replicate trees can be summarized with phylo consensus
+		if *replicateFile != "" {
+			if err := WriteLines(*replicateFile, trees...); err != nil {
+				return fmt.Errorf("couldn't write the replicate trees to file: %w", err)
+			}
+		}
+		return nil
+	}
+
+	//First we read a distance matrix and species name from a file
+	mtx, speciesName, err := distance.ReadMatrixFromFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	//We then use NeighborJoining, FastNeighborJoining, BIONJ, UPGMA or WPGMA to construct the tree
+	var t nj.Tree
+	var heights map[string]float64
+	switch *method {
+	case NJ:
+		t, err = nj.NeighborJoining(mtx, speciesName)
+	case FastNJ:
+		t, err = nj.FastNeighborJoining(mtx, speciesName)
+	case BIONJ:
+		t, err = nj.BIONJ(mtx, speciesName)
+	case UPGMA:
+		t, heights, err = nj.UPGMA(mtx, speciesName)
+	case WPGMA:
+		t, heights, err = nj.WPGMA(mtx, speciesName)
+	}
+	if err != nil {
+		return err
+	}
+	//print out the constructed tree
+	t.Print()
+	for _, n := range t.ToTree().PostOrder() {
+		if h, ok := heights[n.Label]; ok && !n.IsLeaf() {
+			fmt.Println("Height of", n.Label, h)
+		}
+	}
+
+	//write the tree in Newick format
+	fmt.Println(t.Newick())
+	if err := nj.WriteNewickToFile(t, *out); err != nil {
+		return fmt.Errorf("couldn't write the tree to file: %w", err)
+	}
+	return nil
+}
+
+// WriteLines writes each line to the file followed by a newline.
+func WriteLines(fileName string, lines ...string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(file, strings.Join(lines, "\n")+"\n"); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/distance"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/parsimony"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Small parsimony
+//   phylo parsimony scores.txt [tree.nwk sequences.fasta]
+// The rows and columns of the score matrix are in the order of parsimony.NucList. Without a tree and sequences,
+// the example tree ((v0,v1),(v2,v3)) with the leaf sequences CG, CG, AT and CC is labelled.
+
+// exampleTree is the tree labelled when no tree is given.
+const exampleTree = "((v0,v1),(v2,v3));"
+
+// RunParsimony labels the internal nodes of a tree with the most parsimonious sequences and prints the sequence and
+// the scores at the last position of every node.
+func RunParsimony(args []string) error {
+	flags := flag.NewFlagSet("parsimony", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 1 && flags.NArg() != 3 {
+		return fmt.Errorf("usage: phylo parsimony scores.txt [tree.nwk sequences.fasta]")
+	}
+
+	//Read a parsimony score matrix.
+	mtx, err := parsimony.ReadMatrix(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	nt, err := tree.Parse(exampleTree)
+	if err != nil {
+		return err
+	}
+	seqs := map[string]string{"v0": "CG", "v1": "CG", "v2": "AT", "v3": "CC"}
+
+	//Optionally read the tree from a Newick file and the leaf sequences from an aligned FASTA file
+	if flags.NArg() == 3 {
+		nt, err = tree.ReadFile(flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		speciesName, msa, err := distance.ReadAlignmentFromFile(flags.Arg(2))
+		if err != nil {
+			return err
+		}
+		seqs = make(map[string]string, len(speciesName))
+		for i, name := range speciesName {
+			seqs[name] = msa[i]
+		}
+	}
+	t, names, err := parsimony.FromTree(nt)
+	if err != nil {
+		return err
+	}
+	leaveseq := make([]string, len(names))
+	for i, name := range names {
+		seq, ok := seqs[name]
+		if !ok {
+			return fmt.Errorf("no sequence for %s", name)
+		}
+		leaveseq[i] = seq
+	}
+
+	t, err = parsimony.MinimumParsimony(leaveseq, mtx, t, parsimony.NucList)
+	if err != nil {
+		return err
+	}
+	for n := range t {
+		fmt.Println(t[n].Label())
+		fmt.Println(t[n].Score())
+	}
+	return nil
+}
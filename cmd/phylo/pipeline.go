@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/align"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/distance"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/nj"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/parsimony"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/reconcile"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/splits"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Pipeline
+//   phylo pipeline [options] -dir out -species speciesTree.nwk [-map taxa.txt] genes.fasta ...
+//   phylo pipeline [options] -dir out -from stage [-to stage]
+// The stages run in order, each reading the files of the stages before it from the output directory and writing
+// its own file there:
+//   align      alignment.fasta         the multiple alignment of the genes
+//   distance   distanceMatrix.txt      the distance matrix of the alignment
+//   nj         tree.nwk                the unrooted neighbor-joining tree
+//   root       rootedTree.nwk          the tree rooted by the -root method, with every internal node named
+//   parsimony  ancestors.fasta         the most parsimonious sequence of every internal node of the rooted tree
+//   reconcile  reconciledGeneTree.nwk  the rooted tree with its duplications and speciations, given the species tree
+// After each stage the manifest.txt of the directory lists the stages that are done with their files, so a run can
+// be resumed with -from from any stage whose earlier stages are done, e.g. after editing one of their files.
+// Without a species tree the pipeline stops after parsimony.
+
+// Pipeline stages, in the order they run
+const (
+	StageAlign     = "align"
+	StageDistance  = "distance"
+	StageNJ        = "nj"
+	StageRoot      = "root"
+	StageParsimony = "parsimony"
+	StageReconcile = "reconcile"
+)
+
+// Files written to the output directory
+const (
+	ManifestFile   = "manifest.txt"
+	AlignmentFile  = "alignment.fasta"
+	MatrixFile     = "distanceMatrix.txt"
+	TreeFile       = "tree.nwk"
+	RootedTreeFile = "rootedTree.nwk"
+	AncestorsFile  = "ancestors.fasta"
+	ReconciledFile = "reconciledGeneTree.nwk"
+)
+
+// Stage is one step of the pipeline: the file it writes in the output directory and the function that writes it.
+type Stage struct {
+	Name, File string
+	Run        func(p *Pipeline) error
+}
+
+// Stages lists the pipeline stages in the order they run.
+var Stages = []Stage{
+	{StageAlign, AlignmentFile, (*Pipeline).Align},
+	{StageDistance, MatrixFile, (*Pipeline).Distance},
+	{StageNJ, TreeFile, (*Pipeline).NJ},
+	{StageRoot, RootedTreeFile, (*Pipeline).Root},
+	{StageParsimony, AncestorsFile, (*Pipeline).Parsimony},
+	{StageReconcile, ReconciledFile, (*Pipeline).Reconcile},
+}
+
+// Pipeline holds the inputs and options of a pipeline run.
+type Pipeline struct {
+	Dir         string
+	Genes       []string       // FASTA files of the genes
+	Options     align.Options  // options of the multiple alignment
+	Alphabet    align.Alphabet // alphabet the genes are read in
+	Model       string         // distance model
+	Gaps        string         // gap handling of the distance
+	Rooting     *RootFlags     // how the neighbor-joining tree is rooted
+	ScoreFile   string         // parsimony score matrix, every change costs 1 if empty
+	SpeciesTree string         // species tree file
+	TaxonFile   string         // file of gene<TAB>species lines, if the gene names are not species names
+}
+
+// RunPipeline runs the pipeline stages on the FASTA files given in args.
+func RunPipeline(args []string) error {
+	flags := flag.NewFlagSet("pipeline", flag.ExitOnError)
+	alignFlags := AddAlignFlags(flags)
+	p := &Pipeline{}
+	flags.StringVar(&p.Dir, "dir", "pipeline", "output directory")
+	flags.StringVar(&p.Model, "model", distance.Count, "distance model: count, p, jc, k2p, tn93 or logdet")
+	flags.StringVar(&p.Gaps, "gaps", "", "gap handling: pairwise, complete or mismatch (default mismatch for count, pairwise otherwise)")
+	p.Rooting = AddRootFlags(flags, "root")
+	flags.StringVar(&p.ScoreFile, "scores", "", "parsimony score matrix file in the order A T C G - (default every change costs 1)")
+	flags.StringVar(&p.SpeciesTree, "species", "", "rooted binary species tree to reconcile the gene tree with")
+	flags.StringVar(&p.TaxonFile, "map", "", "file of gene<TAB>species lines giving the species of each gene")
+	from := flags.String("from", StageAlign, "first stage to run: align, distance, nj, root, parsimony or reconcile")
+	to := flags.String("to", "", "last stage to run (default reconcile, or parsimony without -species)")
+	flags.Parse(args)
+	p.Genes = flags.Args()
+
+	var err error
+	p.Options, err = alignFlags.Options()
+	if err != nil {
+		return err
+	}
+	p.Alphabet, err = alignFlags.Alphabet(p.Options.Scores)
+	if err != nil {
+		return err
+	}
+	//the distance models and the parsimony stage only score nucleotides
+	if p.Alphabet.Name == align.Protein.Name || align.MatrixAlphabet(p.Options.Scores).Name == align.Protein.Name {
+		return fmt.Errorf("the pipeline aligns nucleotides, not proteins scored with the %s matrix", p.Options.Scores.Name)
+	}
+	if *to == "" {
+		*to = StageReconcile
+		if p.SpeciesTree == "" {
+			*to = StageParsimony
+		}
+	}
+	first, last := StageIndex(*from), StageIndex(*to)
+	if first < 0 || last < 0 || first > last {
+		return fmt.Errorf("can't run the stages from %q to %q", *from, *to)
+	}
+	if first == 0 && len(p.Genes) == 0 {
+		return fmt.Errorf("usage: phylo pipeline [options] genes.fasta ...")
+	}
+	if last >= StageIndex(StageReconcile) && p.SpeciesTree == "" {
+		return fmt.Errorf("the reconcile stage needs a -species tree")
+	}
+	return p.Run(first, last)
+}
+
+// StageIndex returns the position of a stage in Stages, or -1 if there is no such stage.
+func StageIndex(name string) int {
+	for i, stage := range Stages {
+		if stage.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Run runs the stages first to last, after checking in the manifest that the stages before them are done.
+func (p *Pipeline) Run(first, last int) error {
+	done, err := ReadManifest(p.Path(ManifestFile))
+	if err != nil {
+		return err
+	}
+	for _, stage := range Stages[:first] {
+		if done[stage.Name] == "" {
+			return fmt.Errorf("can't start at %s: the %s stage is not done in %s", Stages[first].Name, stage.Name, p.Dir)
+		}
+		if _, err := os.Stat(p.Path(done[stage.Name])); err != nil {
+			return fmt.Errorf("can't start at %s: %w", Stages[first].Name, err)
+		}
+	}
+	//the stages from first on are run again, so what they wrote before is out of date
+	for _, stage := range Stages[first:] {
+		delete(done, stage.Name)
+	}
+	if err := os.MkdirAll(p.Dir, 0755); err != nil {
+		return err
+	}
+	for _, stage := range Stages[first : last+1] {
+		fmt.Println("Stage", stage.Name+":")
+		if err := stage.Run(p); err != nil {
+			return fmt.Errorf("%s: %w", stage.Name, err)
+		}
+		done[stage.Name] = stage.File
+		if err := WriteManifest(p.Path(ManifestFile), done); err != nil {
+			return fmt.Errorf("couldn't write the manifest: %w", err)
+		}
+	}
+	return nil
+}
+
+// Path returns the path of a file in the output directory.
+func (p *Pipeline) Path(file string) string {
+	return filepath.Join(p.Dir, file)
+}
+
+// ReadManifest reads the stages that are done and their files from a manifest. A missing manifest has no stages.
+func ReadManifest(fileName string) (map[string]string, error) {
+	done := make(map[string]string)
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 2 || StageIndex(fields[0]) < 0 {
+			return nil, fmt.Errorf("%s: wrong format of manifest line %q", fileName, line)
+		}
+		done[fields[0]] = fields[1]
+	}
+	return done, scanner.Err()
+}
+
+// WriteManifest writes the stages that are done, in pipeline order, each with its file.
+func WriteManifest(fileName string, done map[string]string) error {
+	lines := []string{"# stage\tfile, written by phylo pipeline"}
+	for _, stage := range Stages {
+		if file, ok := done[stage.Name]; ok {
+			lines = append(lines, stage.Name+"\t"+file)
+		}
+	}
+	return WriteLines(fileName, lines...)
+}
+
+// Align aligns the genes and writes the alignment.
+func (p *Pipeline) Align() error {
+	speciesName, genes, err := ReadGenes(p.Genes, p.Alphabet)
+	if err != nil {
+		return err
+	}
+	msa, _, err := align.Align(genes, p.Options)
+	if err != nil {
+		return err
+	}
+	if err := align.WriteAlignmentToFile(speciesName, msa, "fasta", p.Path(AlignmentFile)); err != nil {
+		return fmt.Errorf("couldn't write the alignment to file: %w", err)
+	}
+	fmt.Println("Aligned", len(genes), "genes, sum-of-pairs score:", align.SumOfPairsScore(msa, p.Options.Scores, p.Options.GapOpen, p.Options.GapExtend))
+	return nil
+}
+
+// Distance writes the distance matrix of the alignment.
+func (p *Pipeline) Distance() error {
+	speciesName, msa, err := distance.ReadAlignmentFromFile(p.Path(AlignmentFile))
+	if err != nil {
+		return err
+	}
+	dmatrix, err := distance.EvolutionaryDistanceMatrix(speciesName, msa, p.Model, p.Gaps)
+	if err != nil {
+		return err
+	}
+	if err := distance.WriteMatrixToFile(speciesName, dmatrix, p.Path(MatrixFile)); err != nil {
+		return fmt.Errorf("couldn't write the distance matrix to file: %w", err)
+	}
+	fmt.Println("Distance matrix of", len(speciesName), "sequences with the", p.Model, "model")
+	return nil
+}
+
+// NJ writes the neighbor-joining tree of the distance matrix.
+func (p *Pipeline) NJ() error {
+	mtx, speciesName, err := distance.ReadMatrixFromFile(p.Path(MatrixFile))
+	if err != nil {
+		return err
+	}
+	t, err := nj.NeighborJoining(mtx, speciesName)
+	if err != nil {
+		return err
+	}
+	if err := nj.WriteNewickToFile(t, p.Path(TreeFile)); err != nil {
+		return fmt.Errorf("couldn't write the tree to file: %w", err)
+	}
+	fmt.Println(t.Newick())
+	return nil
+}
+
+// Root roots the neighbor-joining tree and names the internal nodes that have no label.
+func (p *Pipeline) Root() error {
+	t, err := tree.ReadFile(p.Path(TreeFile))
+	if err != nil {
+		return err
+	}
+	t, err = p.Rooting.Root(t, p.SpeciesTree, p.TaxonFile)
+	if err != nil {
+		return err
+	}
+	t.NameInternalNodes("Internal")
+	if err := tree.WriteFile(p.Path(RootedTreeFile), t); err != nil {
+		return fmt.Errorf("couldn't write the tree to file: %w", err)
+	}
+	fmt.Println(t.Newick())
+	return nil
+}
+
+// Parsimony writes the most parsimonious sequences of the internal nodes of the rooted tree.
+func (p *Pipeline) Parsimony() error {
+	t, err := tree.ReadFile(p.Path(RootedTreeFile))
+	if err != nil {
+		return err
+	}
+	speciesName, msa, err := distance.ReadAlignmentFromFile(p.Path(AlignmentFile))
+	if err != nil {
+		return err
+	}
+	//RNA alignments have U in the place of T in the order of the score matrix
+	nucList := parsimony.NucList
+	seqs := make(map[string]string, len(speciesName))
+	for i, name := range speciesName {
+		seqs[name] = msa[i]
+		if strings.Contains(msa[i], "U") {
+			nucList = []string{"A", "U", "C", "G", "-"}
+		}
+	}
+	mtx := parsimony.UnitMatrix(len(nucList))
+	if p.ScoreFile != "" {
+		mtx, err = parsimony.ReadMatrix(p.ScoreFile)
+		if err != nil {
+			return err
+		}
+	}
+	ancestors, err := parsimony.Ancestors(t, seqs, mtx, nucList)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(ancestors))
+	for name := range ancestors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	seqList := make([]string, len(names))
+	for i, name := range names {
+		seqList[i] = ancestors[name]
+	}
+	if err := align.WriteAlignmentToFile(names, seqList, "fasta", p.Path(AncestorsFile)); err != nil {
+		return fmt.Errorf("couldn't write the ancestral sequences to file: %w", err)
+	}
+	fmt.Println("Ancestral sequences of", len(names), "internal nodes")
+	return nil
+}
+
+// Reconcile labels the duplications and speciations of the rooted tree against the species tree.
+func (p *Pipeline) Reconcile() error {
+	t, err := tree.ReadFile(p.Path(RootedTreeFile))
+	if err != nil {
+		return err
+	}
+	if p.TaxonFile != "" {
+		taxon, err := splits.ReadTaxonMapFromFile(p.TaxonFile)
+		if err != nil {
+			return err
+		}
+		//several genes can belong to one species, so the leaves don't have to stay unique
+		for _, leaf := range t.Leaves() {
+			if name, ok := taxon[leaf.Label]; ok {
+				leaf.Label = name
+			}
+		}
+	}
+	geneTree, err := reconcile.FromTree(t)
+	if err != nil {
+		return err
+	}
+	speciesTree, err := reconcile.ReadNewickFromFile(p.SpeciesTree)
+	if err != nil {
+		return fmt.Errorf("couldn't read the species tree: %w", err)
+	}
+	if err := reconcile.LabelEvents(geneTree, speciesTree); err != nil {
+		return err
+	}
+	if err := reconcile.WriteNewickToFile(geneTree, speciesTree, p.Path(ReconciledFile)); err != nil {
+		return fmt.Errorf("couldn't write the gene tree to file: %w", err)
+	}
+	fmt.Println(reconcile.EventNewick(geneTree, speciesTree))
+	fmt.Println("Duplications:", reconcile.Duplications(geneTree))
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// manifestStages returns the stages listed in the manifest of a directory, in pipeline order.
+func manifestStages(t *testing.T, dir string) []string {
+	done, err := ReadManifest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stages := make([]string, 0)
+	for _, stage := range Stages {
+		if file, ok := done[stage.Name]; ok {
+			if file != stage.File {
+				t.Errorf("manifest lists %s for the %s stage, want %s", file, stage.Name, stage.File)
+			}
+			stages = append(stages, stage.Name)
+		}
+	}
+	return stages
+}
+
+func TestPipelineResume(t *testing.T) {
+	genes, err := filepath.Glob("../../data/*_p53.fasta")
+	if err != nil || len(genes) == 0 {
+		t.Fatalf("no p53 genes in data: %v", err)
+	}
+	dir := t.TempDir()
+	species := []string{"-dir", dir, "-species", "../../data/mammals.nwk", "-map", "../../data/taxa.txt"}
+	all := []string{StageAlign, StageDistance, StageNJ, StageRoot, StageParsimony, StageReconcile}
+
+	// nothing is done in a new directory
+	if err := RunPipeline(append(species, "-from", StageDistance)); err == nil || !strings.Contains(err.Error(), "align stage is not done") {
+		t.Errorf("started at distance in an empty directory: %v", err)
+	}
+
+	if err := RunPipeline(append(species, genes...)); err != nil {
+		t.Fatal(err)
+	}
+	if got := manifestStages(t, dir); !reflect.DeepEqual(got, all) {
+		t.Fatalf("manifest lists %v after a full run, want %v", got, all)
+	}
+	for _, stage := range Stages {
+		if _, err := os.Stat(filepath.Join(dir, stage.File)); err != nil {
+			t.Errorf("%s stage: %v", stage.Name, err)
+		}
+	}
+
+	// running nj again makes the stages after it out of date
+	if err := RunPipeline(append(species, "-from", StageNJ, "-to", StageNJ)); err != nil {
+		t.Fatal(err)
+	}
+	if got := manifestStages(t, dir); !reflect.DeepEqual(got, all[:3]) {
+		t.Errorf("manifest lists %v after rerunning nj, want %v", got, all[:3])
+	}
+	if err := RunPipeline(append(species, "-from", StageReconcile)); err == nil || !strings.Contains(err.Error(), "root stage is not done") {
+		t.Errorf("started at reconcile without the root stage: %v", err)
+	}
+	if err := RunPipeline(append(species, "-from", StageRoot)); err != nil {
+		t.Fatal(err)
+	}
+	if got := manifestStages(t, dir); !reflect.DeepEqual(got, all) {
+		t.Errorf("manifest lists %v after resuming at root, want %v", got, all)
+	}
+
+	// a stage listed in the manifest whose file is gone is not done
+	if err := os.Remove(filepath.Join(dir, MatrixFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunPipeline(append(species, "-from", StageNJ)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("started at nj without the distance matrix: %v", err)
+	}
+	// the refused run leaves the manifest as it was
+	if got := manifestStages(t, dir); !reflect.DeepEqual(got, all) {
+		t.Errorf("manifest lists %v after a refused run, want %v", got, all)
+	}
+	if err := RunPipeline(append(species, "-from", StageDistance, "-to", StageNJ)); err != nil {
+		t.Fatal(err)
+	}
+	if got := manifestStages(t, dir); !reflect.DeepEqual(got, all[:3]) {
+		t.Errorf("manifest lists %v after rerunning distance and nj, want %v", got, all[:3])
+	}
+
+	// stages must be given in order, and the first stage needs genes
+	for _, args := range [][]string{{"-from", StageRoot, "-to", StageNJ}, {"-from", "bootstrap"}, {}} {
+		if err := RunPipeline(append(species, args...)); err == nil {
+			t.Errorf("ran the pipeline with %v", args)
+		}
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, ManifestFile)
+
+	// a missing manifest has no stages
+	done, err := ReadManifest(fileName)
+	if err != nil || len(done) != 0 {
+		t.Errorf("ReadManifest of a missing file = %v, %v, want no stages", done, err)
+	}
+
+	// comments and blank lines are skipped
+	want := map[string]string{StageAlign: AlignmentFile, StageDistance: MatrixFile}
+	if err := WriteManifest(fileName, want); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileName, append([]byte("\n# edited by hand\n"), mustRead(t, fileName)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	done, err = ReadManifest(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(done, want) {
+		t.Errorf("ReadManifest = %v, want %v", done, want)
+	}
+
+	for _, bad := range []string{"align\n", "align alignment.fasta\n", "bootstrap\ttrees.nwk\n", "align\talignment.fasta\textra\n"} {
+		if err := os.WriteFile(fileName, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadManifest(fileName); err == nil {
+			t.Errorf("ReadManifest accepted the line %q", bad)
+		}
+		p := &Pipeline{Dir: dir}
+		if err := p.Run(StageIndex(StageDistance), StageIndex(StageDistance)); err == nil {
+			t.Errorf("ran the pipeline with the manifest line %q", bad)
+		}
+	}
+}
+
+// mustRead returns the contents of a file.
+func mustRead(t *testing.T, fileName string) []byte {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/reconcile"
+)
+
+// Reconciliation
+//   phylo reconcile [-out reconciledGeneTree.nwk] geneTree.nwk speciesTree.nwk
+//   phylo reconcile -method umpr [-loss 3] [-dup 2] [-transfer 1] [-ages speciesAges.txt] [geneTree.nwk speciesTree.nwk]
+// The lca method labels every internal node of the gene tree as a duplication or a speciation and writes the gene
+// tree with its events. The umpr method prints the optimal cost of a reconciliation with duplications, transfers
+// and losses, one optimal reconciliation, their number and the support of each event; without trees it reconciles
+// the example gene tree (((A,C),B),D) with the species tree (((A,B),C),D).
+
+// Reconciliation methods
+const (
+	LCA  = "lca"
+	UMPR = "umpr"
+)
+
+const (
+	exampleGeneTree    = "(((A,C),B),D);"
+	exampleSpeciesTree = "(((A,B),C),D);"
+)
+
+// RunReconcile reconciles the gene tree with the species tree given in args.
+func RunReconcile(args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	method := flags.String("method", LCA, "reconciliation method: lca or umpr")
+	out := flags.String("out", "reconciledGeneTree.nwk", "output Newick file of the lca method")
+	loss := flags.Int("loss", 3, "cost of a loss (umpr)")
+	dup := flags.Int("dup", 2, "cost of a duplication (umpr)")
+	transfer := flags.Int("transfer", 1, "cost of a transfer (umpr)")
+	//-ages reads divergence times of the species tree, so transfers are only allowed between contemporary branches
+	ageFile := flags.String("ages", "", "file of label<TAB>age divergence times of the species tree (umpr)")
+	flags.Parse(args)
+	if flags.NArg() != 2 && (flags.NArg() != 0 || *method != UMPR) {
+		return fmt.Errorf("usage: phylo reconcile [options] geneTree.nwk speciesTree.nwk")
+	}
+
+	var geneTree, speciesTree reconcile.Tree
+	var err error
+	if flags.NArg() == 2 {
+		geneTree, err = reconcile.ReadNewickFromFile(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("couldn't read the gene tree: %w", err)
+		}
+		speciesTree, err = reconcile.ReadNewickFromFile(flags.Arg(1))
+		if err != nil {
+			return fmt.Errorf("couldn't read the species tree: %w", err)
+		}
+	} else {
+		geneTree, _ = reconcile.ParseNewick(exampleGeneTree)
+		speciesTree, _ = reconcile.ParseNewick(exampleSpeciesTree)
+	}
+
+	switch *method {
+	case LCA:
+		return LabelGeneTreeEvents(geneTree, speciesTree, *out)
+	case UMPR:
+		if *ageFile != "" {
+			if err := reconcile.ReadAgesFromFile(*ageFile, speciesTree); err != nil {
+				return fmt.Errorf("couldn't read the species ages: %w", err)
+			}
+			reconcile.PrintTimeSlices(reconcile.TimeSlices(speciesTree))
+		}
+		return ReconcileUMPR(geneTree, speciesTree, *loss, *dup, *transfer)
+	}
+	return fmt.Errorf("unknown reconciliation method %s", *method)
+}
+
+// LabelGeneTreeEvents labels the events of the gene tree, prints them and writes the gene tree with its events.
+func LabelGeneTreeEvents(geneTree, speciesTree reconcile.Tree, outFile string) error {
+	fmt.Println("Label gene tree events.")
+	if err := reconcile.LabelEvents(geneTree, speciesTree); err != nil {
+		return err
+	}
+	for i := range geneTree {
+		fmt.Println(geneTree[i].Label())
+		fmt.Println(geneTree[i].Event())
+	}
+	fmt.Println(reconcile.EventNewick(geneTree, speciesTree))
+	if err := reconcile.WriteNewickToFile(geneTree, speciesTree, outFile); err != nil {
+		return fmt.Errorf("couldn't write the gene tree to file: %w", err)
+	}
+	return nil
+}
+
+// ReconcileUMPR prints the optimal cost of reconciling the gene tree with the species tree, one optimal
+// reconciliation, the number of optimal reconciliations and the support of each event.
+func ReconcileUMPR(geneTree, speciesTree reconcile.Tree, loss, dup, transfer int) error {
+	fmt.Println("Reconciliation using U-MPR")
+	cost, err := reconcile.UMPR(geneTree, speciesTree, len(geneTree), len(speciesTree), loss, dup, transfer)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Optimal cost is: ", cost)
+
+	// Rebuild one reconciliation with the optimal cost
+	scenario, err := reconcile.Traceback(geneTree, speciesTree, loss)
+	if err != nil {
+		return err
+	}
+	reconcile.PrintScenario(scenario)
+
+	// Count all reconciliations with the optimal cost and how well each event is supported
+	fmt.Println("Number of optimal reconciliations: ", reconcile.CountScenarios(geneTree, speciesTree, loss))
+	reconcile.PrintEventSupports(reconcile.EventSupports(geneTree, speciesTree, loss))
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/reconcile"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/splits"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Rooting
+//   phylo root [-method outgroup|midpoint|minvar] [-outgroup a,b] [-out rootedTree.nwk] tree.nwk
+//   phylo root -method duplications|umpr -species speciesTree.nwk [-map taxa.txt] [-out rootedTree.nwk] tree.nwk
+// Neighbor-joining trees are unrooted; parsimony and reconciliation need rooted binary trees. The duplications and
+// umpr methods root the gene tree on the branch whose rooting reconciles best with the species tree.
+
+// Rooting methods; the umpr method is named by the UMPR reconciliation method
+const (
+	Outgroup     = "outgroup"
+	Midpoint     = "midpoint"
+	MinVariance  = "minvar"
+	Duplications = "duplications"
+)
+
+// RootFlags are the flags that choose how a tree is rooted, shared by the root and pipeline commands.
+type RootFlags struct {
+	method, outgroup    *string
+	loss, dup, transfer *int
+}
+
+// AddRootFlags defines the rooting flags on a flag set, with the rooting method flag named methodFlag.
+func AddRootFlags(flags *flag.FlagSet, methodFlag string) *RootFlags {
+	f := &RootFlags{}
+	f.method = flags.String(methodFlag, "", "rooting method: outgroup, midpoint, minvar, duplications or umpr (default outgroup with -outgroup, midpoint otherwise)")
+	//-outgroup takes one leaf or a comma-separated list of leaves that are together on one side of a branch
+	f.outgroup = flags.String("outgroup", "", "outgroup leaves, separated by commas")
+	f.loss = flags.Int("loss", 3, "cost of a loss (umpr rooting)")
+	f.dup = flags.Int("dup", 2, "cost of a duplication (umpr rooting)")
+	f.transfer = flags.Int("transfer", 1, "cost of a transfer (umpr rooting)")
+	return f
+}
+
+// Method returns the rooting method given by the flags.
+func (f *RootFlags) Method() string {
+	if *f.method != "" {
+		return *f.method
+	}
+	if *f.outgroup != "" {
+		return Outgroup
+	}
+	return Midpoint
+}
+
+// Root roots the tree with the method given by the flags. The duplications and umpr methods reconcile it with the
+// species tree file, after renaming its leaves with the taxon file if there is one.
+func (f *RootFlags) Root(t *tree.Tree, speciesFile, taxonFile string) (*tree.Tree, error) {
+	switch f.Method() {
+	case Outgroup:
+		if *f.outgroup == "" {
+			return nil, fmt.Errorf("outgroup rooting needs -outgroup")
+		}
+		return t, t.RootOutgroup(strings.Split(*f.outgroup, ",")...)
+	case Midpoint:
+		return t, t.RootMidpoint()
+	case MinVariance:
+		return t, t.RootMinVariance()
+	case Duplications, UMPR:
+		if speciesFile == "" {
+			return nil, fmt.Errorf("%s rooting needs a -species tree", f.Method())
+		}
+		speciesTree, err := reconcile.ReadNewickFromFile(speciesFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read the species tree: %w", err)
+		}
+		var taxon map[string]string
+		if taxonFile != "" {
+			taxon, err = splits.ReadTaxonMapFromFile(taxonFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if f.Method() == Duplications {
+			rooted, duplications, err := reconcile.RootByDuplications(t, speciesTree, taxon)
+			if err == nil {
+				fmt.Println("Duplications:", duplications)
+			}
+			return rooted, err
+		}
+		rooted, cost, err := reconcile.RootByUMPR(t, speciesTree, taxon, *f.loss, *f.dup, *f.transfer)
+		if err == nil {
+			fmt.Println("Optimal cost is: ", cost)
+		}
+		return rooted, err
+	}
+	return nil, fmt.Errorf("unknown rooting method %s", f.Method())
+}
+
+// RunRoot roots the tree of the Newick file given in args and writes it.
+func RunRoot(args []string) error {
+	flags := flag.NewFlagSet("root", flag.ExitOnError)
+	rootFlags := AddRootFlags(flags, "method")
+	speciesFile := flags.String("species", "", "rooted binary species tree (duplications and umpr rooting)")
+	taxonFile := flags.String("map", "", "file of gene<TAB>species lines giving the species of each gene")
+	out := flags.String("out", "rootedTree.nwk", "output Newick file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: phylo root [options] tree.nwk")
+	}
+
+	t, err := tree.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	t, err = rootFlags.Root(t, *speciesFile, *taxonFile)
+	if err != nil {
+		return err
+	}
+	fmt.Println(t.Newick())
+	if err := tree.WriteFile(*out, t); err != nil {
+		return fmt.Errorf("couldn't write the tree to file: %w", err)
+	}
+	return nil
+}
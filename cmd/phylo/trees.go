@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/distance"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/splits"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Tree collections
+// Commands that work on sets of Newick trees over the same taxa, such as the trees written by phylo nj
+// (bootstrap replicates or trees of several genes) and the rooted trees used by phylo reconcile.
+//   phylo consensus [-method strict|majority|greedy] [-threshold 0.5] [-rooted] [-out consensus.nwk] trees.nwk ...
+//   phylo treedist [-metric rf|nrf|wrf|quartet|ms] [-rooted] [-map taxa.txt] [-out treeDistances.txt] tree1.nwk ...
+
+// RunConsensus reads the trees of the files given in args and writes their consensus tree.
+func RunConsensus(args []string) error {
+	flags := flag.NewFlagSet("consensus", flag.ExitOnError)
+	method := flags.String("method", splits.Majority, "consensus method: strict, majority or greedy")
+	threshold := flags.Float64("threshold", 0.5, "fraction of trees a split must be in for majority-rule consensus")
+	rooted := flags.Bool("rooted", false, "compare clades of rooted trees instead of splits of unrooted trees")
+	out := flags.String("out", "consensus.nwk", "output Newick file")
+	flags.Parse(args)
+
+	trees, err := splits.ReadTreesFromFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	consensus, err := splits.Consensus(trees, *method, *threshold, *rooted)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Consensus of", len(trees), "trees:")
+	fmt.Println(consensus.Newick())
+	if err := tree.WriteFile(*out, consensus); err != nil {
+		return fmt.Errorf("couldn't write the tree to file: %w", err)
+	}
+	return nil
+}
+
+// RunTreeDistance reads the trees of the files given in args and writes the matrix of distances between them, in
+// the format read by phylo nj.
+func RunTreeDistance(args []string) error {
+	flags := flag.NewFlagSet("treedist", flag.ExitOnError)
+	metric := flags.String("metric", splits.RF, "tree distance: rf, nrf, wrf, quartet or ms")
+	rooted := flags.Bool("rooted", false, "compare clades of rooted trees instead of splits of unrooted trees")
+	taxonFile := flags.String("map", "", "file of label<TAB>taxon lines renaming leaves, to compare trees of different genes")
+	out := flags.String("out", "treeDistances.txt", "output distance matrix file")
+	flags.Parse(args)
+
+	trees, names, err := splits.ReadNamedTrees(flags.Args())
+	if err != nil {
+		return err
+	}
+	if *taxonFile != "" {
+		taxon, err := splits.ReadTaxonMapFromFile(*taxonFile)
+		if err == nil {
+			err = splits.RenameLeaves(trees, taxon)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	matrix, err := splits.DistanceMatrix(trees, *metric, *rooted)
+	if err != nil {
+		return err
+	}
+	for i := range matrix {
+		fmt.Print(names[i])
+		for j := range matrix[i] {
+			fmt.Print("\t", strconv.FormatFloat(matrix[i][j], 'g', 6, 64))
+		}
+		fmt.Println()
+	}
+	if err := distance.WriteMatrixToFile(names, matrix, *out); err != nil {
+		return fmt.Errorf("couldn't write the distance matrix to file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package distance
+
+import (
+	"fmt"
+	"math"
+)
+
+//Evolutionary distances
+//The number of differences between two sequences underestimates the number of substitutions on a long branch,
+//because later substitutions hide earlier ones at the same site. The models below correct the proportion of
+//differences for these multiple hits:
+//  count   number of differing sites, no correction (any letters)
+//  p       proportion of differing sites, no correction (any letters)
+//  jc      Jukes-Cantor, equal base frequencies and substitution rates
+//  k2p     Kimura 2-parameter, separate transition and transversion rates
+//  tn93    Tamura-Nei, unequal base frequencies and separate rates for A<->G, C<->T and transversions
+//  logdet  LogDet/paralinear distance, allows base frequencies to differ between the sequences
+//All but p compare nucleotides only; sites with an ambiguous nucleotide are left out of the pair.
+//Gaps are handled in one of three ways:
+//  pairwise  leave out the sites with a gap in either sequence of the pair
+//  complete  leave out the columns with a gap in any sequence of the alignment
+//  mismatch  count a gap against a nucleotide as a difference (a transversion for k2p and tn93; not for logdet)
+//Without a gap handling, count uses mismatch, which gives the mismatch counts the programs have always used,
+//and the other models use pairwise.
+
+//Distance models
+const (
+	Count       = "count"
+	PDistance   = "p"
+	JukesCantor = "jc"
+	Kimura2P    = "k2p"
+	TamuraNei   = "tn93"
+	LogDet      = "logdet"
+)
+
+//Gap handling
+const (
+	PairwiseDeletion = "pairwise"
+	CompleteDeletion = "complete"
+	GapsAsMismatch   = "mismatch"
+)
+
+//nucleotideIndex maps A, C, G and T (or U) to 0-3 and every other letter to -1.
+var nucleotideIndex = NucleotideIndex()
+
+//NucleotideIndex returns the index of each nucleotide: A 0, C 1, G 2, T and U 3, anything else -1.
+func NucleotideIndex() [256]int {
+	var index [256]int
+	for i := range index {
+		index[i] = -1
+	}
+	for i, nuc := range "ACGT" {
+		index[nuc] = i
+		index[nuc+'a'-'A'] = i
+	}
+	index['U'] = 3
+	index['u'] = 3
+	return index
+}
+
+//DefaultGaps returns the gap handling used with a distance model when none is given.
+func DefaultGaps(model string) string {
+	if model == Count {
+		return GapsAsMismatch
+	}
+	return PairwiseDeletion
+}
+
+//EvolutionaryDistanceMatrix takes a multiple alignment, a distance model and a gap handling, and returns the
+//matrix of the distances between every pair of sequences.
+func EvolutionaryDistanceMatrix(speciesName, msa []string, model, gaps string) ([][]float64, error) {
+	switch model {
+	case Count, PDistance, JukesCantor, Kimura2P, TamuraNei, LogDet:
+	default:
+		return nil, fmt.Errorf("unknown distance model %q", model)
+	}
+	if err := CheckAlignment(speciesName, msa); err != nil {
+		return nil, err
+	}
+	if gaps == "" {
+		gaps = DefaultGaps(model)
+	}
+	switch gaps {
+	case PairwiseDeletion, CompleteDeletion:
+	case GapsAsMismatch:
+		if model == LogDet {
+			return nil, fmt.Errorf("gaps cannot be counted as mismatches with the %s distance", model)
+		}
+	default:
+		return nil, fmt.Errorf("unknown gap handling %q", gaps)
+	}
+	if gaps == CompleteDeletion {
+		msa = RemoveGappedColumns(msa)
+	}
+
+	dmatrix := make([][]float64, len(msa))
+	for i := range dmatrix {
+		dmatrix[i] = make([]float64, len(msa))
+	}
+	for i := 0; i < len(msa); i++ {
+		for j := i + 1; j < len(msa); j++ {
+			d, err := PairDistance(msa[i], msa[j], model, gaps == GapsAsMismatch)
+			if err != nil {
+				return nil, fmt.Errorf("%s and %s: %w", speciesName[i], speciesName[j], err)
+			}
+			dmatrix[i][j] = d
+			dmatrix[j][i] = d
+		}
+	}
+	return dmatrix, nil
+}
+
+//MismatchMatrix returns the matrix of the number of differing columns between every pair of sequences of a
+//multiple alignment, a gap against a letter included. It is the count distance with gaps as mismatches.
+func MismatchMatrix(msa []string) [][]float64 {
+	dmatrix := make([][]float64, len(msa))
+	for i := range dmatrix {
+		dmatrix[i] = make([]float64, len(msa))
+	}
+	for i := 0; i < len(msa); i++ {
+		for j := i + 1; j < len(msa); j++ {
+			mismatch := 0
+			for k := 0; k < len(msa[i]); k++ {
+				if msa[i][k] != msa[j][k] {
+					mismatch++
+				}
+			}
+			dmatrix[i][j] = float64(mismatch)
+			dmatrix[j][i] = float64(mismatch)
+		}
+	}
+	return dmatrix
+}
+
+//CheckAlignment returns an error if the sequences of a multiple alignment don't have one name each or don't
+//all have the same length.
+func CheckAlignment(speciesName, msa []string) error {
+	if len(speciesName) != len(msa) {
+		return fmt.Errorf("%d names for %d sequences", len(speciesName), len(msa))
+	}
+	for i := range msa {
+		if len(msa[i]) != len(msa[0]) {
+			return fmt.Errorf("%s has length %d, not %d like %s", speciesName[i], len(msa[i]), len(msa[0]), speciesName[0])
+		}
+	}
+	return nil
+}
+
+//RemoveGappedColumns returns a multiple alignment without the columns that have a gap in any sequence.
+func RemoveGappedColumns(msa []string) []string {
+	rows := make([][]byte, len(msa))
+	length := 0
+	if len(msa) > 0 {
+		length = len(msa[0])
+	}
+	for col := 0; col < length; col++ {
+		gapped := false
+		for _, row := range msa {
+			if row[col] == '-' {
+				gapped = true
+				break
+			}
+		}
+		if gapped {
+			continue
+		}
+		for i, row := range msa {
+			rows[i] = append(rows[i], row[col])
+		}
+	}
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		result[i] = string(row)
+	}
+	return result
+}
+
+//PairDistance takes two aligned sequences, a distance model and whether a gap against a letter is a difference,
+//and returns their distance. It returns an error when the sequences are too different for the model.
+func PairDistance(seq1, seq2, model string, gapMismatch bool) (float64, error) {
+	if model == Count || model == PDistance {
+		sites, diffs := 0, 0
+		for k := 0; k < len(seq1); k++ {
+			gap1, gap2 := seq1[k] == '-', seq2[k] == '-'
+			if gap1 && gap2 || (gap1 || gap2) && !gapMismatch {
+				continue
+			}
+			sites++
+			if seq1[k] != seq2[k] {
+				diffs++
+			}
+		}
+		if model == Count {
+			return float64(diffs), nil
+		}
+		if sites == 0 {
+			return 0, fmt.Errorf("no sites to compare")
+		}
+		return float64(diffs) / float64(sites), nil
+	}
+
+	//counts[a][b] is the number of sites with nucleotide a in seq1 and b in seq2; gapDiffs counts gaps against nucleotides
+	var counts [4][4]int
+	sites, gapDiffs := 0, 0
+	for k := 0; k < len(seq1); k++ {
+		a, b := nucleotideIndex[seq1[k]], nucleotideIndex[seq2[k]]
+		if a >= 0 && b >= 0 {
+			counts[a][b]++
+			sites++
+		} else if gapMismatch && (seq1[k] == '-' && b >= 0 || seq2[k] == '-' && a >= 0) {
+			gapDiffs++
+			sites++
+		}
+	}
+	if sites == 0 {
+		return 0, fmt.Errorf("no sites to compare")
+	}
+	n := float64(sites)
+	//A<->G and C<->T are transitions, every other difference is a transversion
+	ag := float64(counts[0][2]+counts[2][0]) / n
+	ct := float64(counts[1][3]+counts[3][1]) / n
+	same := float64(counts[0][0]+counts[1][1]+counts[2][2]+counts[3][3]) / n
+	q := 1 - same - ag - ct
+
+	var d float64
+	switch model {
+	case JukesCantor:
+		d = -0.75 * math.Log(1-4.0/3.0*(1-same))
+	case Kimura2P:
+		p := ag + ct
+		d = -0.5*math.Log(1-2*p-q) - 0.25*math.Log(1-2*q)
+	case TamuraNei:
+		d = TamuraNeiDistance(counts, ag, ct, q)
+	case LogDet:
+		d = LogDetDistance(counts)
+	}
+	if math.IsNaN(d) || math.IsInf(d, 0) {
+		return 0, fmt.Errorf("sequences are too different for the %s distance", model)
+	}
+	return d, nil
+}
+
+//TamuraNeiDistance returns the Tamura-Nei distance from the nucleotide pair counts and the proportions of
+//A<->G transitions, C<->T transitions and transversions. Base frequencies are those of the two sequences together.
+func TamuraNeiDistance(counts [4][4]int, ag, ct, q float64) float64 {
+	var freq [4]float64
+	total := 0.0
+	for a := range counts {
+		for b := range counts[a] {
+			freq[a] += float64(counts[a][b])
+			freq[b] += float64(counts[a][b])
+			total += 2 * float64(counts[a][b])
+		}
+	}
+	for i := range freq {
+		freq[i] /= total
+	}
+	piA, piC, piG, piT := freq[0], freq[1], freq[2], freq[3]
+	piR := piA + piG
+	piY := piC + piT
+	if piR == 0 || piY == 0 {
+		return math.NaN()
+	}
+
+	d := 0.0
+	if piA*piG > 0 {
+		d -= 2 * piA * piG / piR * math.Log(1-piR*ag/(2*piA*piG)-q/(2*piR))
+	}
+	if piC*piT > 0 {
+		d -= 2 * piC * piT / piY * math.Log(1-piY*ct/(2*piC*piT)-q/(2*piY))
+	}
+	d -= 2 * (piR*piY - piA*piG*piY/piR - piC*piT*piR/piY) * math.Log(1-q/(2*piR*piY))
+	return d
+}
+
+//LogDetDistance returns the LogDet (paralinear) distance from the nucleotide pair counts:
+//-1/4 (ln det F - 1/2 ln(det Fx det Fy)), where F is the divergence matrix and Fx and Fy hold the base frequencies.
+func LogDetDistance(counts [4][4]int) float64 {
+	total := 0.0
+	for a := range counts {
+		for b := range counts[a] {
+			total += float64(counts[a][b])
+		}
+	}
+	f := make([][]float64, 4)
+	fx := 1.0
+	fy := 1.0
+	for a := range counts {
+		f[a] = make([]float64, 4)
+		rowSum := 0.0
+		colSum := 0.0
+		for b := range counts[a] {
+			f[a][b] = float64(counts[a][b]) / total
+			rowSum += float64(counts[a][b]) / total
+			colSum += float64(counts[b][a]) / total
+		}
+		fx *= rowSum
+		fy *= colSum
+	}
+	det := Determinant(f)
+	if det <= 0 || fx <= 0 || fy <= 0 {
+		return math.NaN()
+	}
+	return -0.25 * (math.Log(det) - 0.5*(math.Log(fx)+math.Log(fy)))
+}
+
+//Determinant returns the determinant of a square matrix by Gaussian elimination with partial pivoting.
+//The matrix is changed.
+func Determinant(m [][]float64) float64 {
+	det := 1.0
+	for col := range m {
+		pivot := col
+		for row := col + 1; row < len(m); row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return 0
+		}
+		if pivot != col {
+			m[pivot], m[col] = m[col], m[pivot]
+			det = -det
+		}
+		det *= m[col][col]
+		for row := col + 1; row < len(m); row++ {
+			factor := m[row][col] / m[col][col]
+			for k := col; k < len(m); k++ {
+				m[row][k] -= factor * m[col][k]
+			}
+		}
+	}
+	return det
+}
@@ -0,0 +1,160 @@
+package distance
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// pairs returns two sequences with count[a][b] sites of nucleotide a in the first and b in the second, in the
+// order A, C, G, T.
+func pairs(count [4][4]int) (string, string) {
+	var seq1, seq2 strings.Builder
+	for a := range count {
+		for b := range count[a] {
+			seq1.WriteString(strings.Repeat("ACGT"[a:a+1], count[a][b]))
+			seq2.WriteString(strings.Repeat("ACGT"[b:b+1], count[a][b]))
+		}
+	}
+	return seq1.String(), seq2.String()
+}
+
+// approx fails the test unless got is within 1e-12 of want.
+func approx(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestPairDistance(t *testing.T) {
+	// 4 differences in 40 sites: A->C and C->A transversions, and A->G and C->T transitions
+	counts := [4][4]int{{8, 1, 1, 0}, {1, 8, 0, 1}, {0, 0, 10, 0}, {0, 0, 0, 10}}
+	seq1, seq2 := pairs(counts)
+	if len(seq1) != 40 {
+		t.Fatalf("test sequences have %d sites, want 40", len(seq1))
+	}
+	p := 0.1
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{Count, 4},
+		{PDistance, p},
+		{JukesCantor, -0.75 * math.Log(1-4*p/3)},
+		// transitions P = 2/40, transversions Q = 2/40
+		{Kimura2P, -0.5*math.Log(1-2*0.05-0.05) - 0.25*math.Log(1-2*0.05)},
+	}
+	for _, test := range tests {
+		got, err := PairDistance(seq1, seq2, test.model, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		approx(t, test.model, got, test.want)
+	}
+
+	// with only transitions, Q = 0 and K2P is -1/2 ln(1-2P)
+	transitions := [4][4]int{{9, 0, 1, 0}, {0, 9, 0, 1}, {1, 0, 9, 0}, {0, 1, 0, 9}}
+	seq1, seq2 = pairs(transitions)
+	got, err := PairDistance(seq1, seq2, Kimura2P, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approx(t, "K2P with transitions only", got, -0.5*math.Log(1-2*0.1))
+
+	// with equal base frequencies and as many A<->G as C<->T transitions, TN93 is K2P
+	equal := [4][4]int{{8, 1, 1, 0}, {1, 8, 0, 1}, {1, 0, 9, 0}, {0, 1, 0, 9}}
+	seq1, seq2 = pairs(equal)
+	k2p, err := PairDistance(seq1, seq2, Kimura2P, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn93, err := PairDistance(seq1, seq2, TamuraNei, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approx(t, "TN93 at equal base frequencies", tn93, k2p)
+
+	// differences spread evenly over all 12 pairs of nucleotides make the divergence matrix of the JC model,
+	// for which LogDet is JC: here p = 12/120
+	var even [4][4]int
+	for a := range even {
+		for b := range even[a] {
+			even[a][b] = 1
+		}
+		even[a][a] = 27
+	}
+	seq1, seq2 = pairs(even)
+	logdet, err := PairDistance(seq1, seq2, LogDet, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approx(t, "LogDet of the JC divergence matrix", logdet, -0.75*math.Log(1-4*p/3))
+	logdet, err = PairDistance(seq1, seq1, LogDet, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approx(t, "LogDet of a sequence with itself", logdet, 0)
+
+	// ambiguous nucleotides are left out of the pair
+	got, err = PairDistance("ACGTN", "ACGAA", JukesCantor, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approx(t, "JC with an N", got, -0.75*math.Log(1-4*0.25/3))
+
+	if _, err := PairDistance("AAAA", "CCCC", JukesCantor, false); err == nil {
+		t.Error("JC distance of sequences with nothing in common")
+	}
+	if _, err := PairDistance("----", "ACGT", PDistance, false); err == nil {
+		t.Error("p-distance of sequences with no sites to compare")
+	}
+}
+
+func TestGapHandling(t *testing.T) {
+	names := []string{"s1", "s2", "s3"}
+	msa := []string{"ACGTAC", "ACGTT-", "-CGTAC"}
+	tests := []struct {
+		model, gaps string
+		s1s2, s1s3  float64
+	}{
+		// pairwise: s1 and s2 share 5 sites, one of them different, as do s1 and s3, all of them the same
+		{PDistance, PairwiseDeletion, 1.0 / 5, 0},
+		// complete: the first and last columns have a gap, leaving CGTA, CGTT and CGTA
+		{PDistance, CompleteDeletion, 1.0 / 4, 0},
+		// mismatch: all 6 columns count, the gaps against letters as differences
+		{PDistance, GapsAsMismatch, 2.0 / 6, 1.0 / 6},
+		{Count, GapsAsMismatch, 2, 1},
+		{Count, PairwiseDeletion, 1, 0},
+		// count defaults to gaps as mismatches
+		{Count, "", 2, 1},
+		{JukesCantor, "", -0.75 * math.Log(1-4.0/15), 0},
+	}
+	for _, test := range tests {
+		d, err := EvolutionaryDistanceMatrix(names, msa, test.model, test.gaps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		approx(t, test.model+" "+test.gaps+" s1-s2", d[0][1], test.s1s2)
+		approx(t, test.model+" "+test.gaps+" s1-s3", d[0][2], test.s1s3)
+		if d[1][0] != d[0][1] || d[0][0] != 0 {
+			t.Errorf("%s %s matrix is not symmetric with a zero diagonal: %v", test.model, test.gaps, d)
+		}
+	}
+
+	if got := RemoveGappedColumns(msa); strings.Join(got, " ") != "CGTA CGTT CGTA" {
+		t.Errorf("RemoveGappedColumns = %v, want [CGTA CGTT CGTA]", got)
+	}
+	if _, err := EvolutionaryDistanceMatrix(names, msa, LogDet, GapsAsMismatch); err == nil {
+		t.Error("LogDet counted gaps as mismatches")
+	}
+	if _, err := EvolutionaryDistanceMatrix(names, msa, "f81", ""); err == nil {
+		t.Error("accepted an unknown model")
+	}
+	if _, err := EvolutionaryDistanceMatrix(names, msa, PDistance, "ignore"); err == nil {
+		t.Error("accepted an unknown gap handling")
+	}
+	if _, err := EvolutionaryDistanceMatrix(names, []string{"ACGT", "ACG", "ACGT"}, PDistance, ""); err == nil {
+		t.Error("accepted sequences of different lengths")
+	}
+}
@@ -0,0 +1,4 @@
+// Package distance computes the distance matrices that the tree building methods start from: mismatch counts and
+// evolutionary distances between the sequences of a multiple alignment. It also reads and writes the distance
+// matrix files and aligned FASTA files used by the phylo command.
+package distance
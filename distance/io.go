@@ -0,0 +1,145 @@
+package distance
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//Distance matrix files
+//The first line holds the number of species, and each following line a species name and its row of the matrix,
+//separated by tabs. This is the layout NeighborJoining has always read.
+
+//ReadMatrixFromFile reads a distance matrix file and returns the matrix and the species names.
+//It returns an error if the matrix is not square or a row doesn't match the number on the first line.
+func ReadMatrixFromFile(fileName string) ([][]float64, []string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			lines = append(lines, scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil, fmt.Errorf("%s: empty file", fileName)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: the first line should be the number of species", fileName)
+	}
+	if len(lines)-1 != n {
+		return nil, nil, fmt.Errorf("%s: %d rows for %d species", fileName, len(lines)-1, n)
+	}
+
+	mtx := make([][]float64, 0, n)
+	speciesName := make([]string, 0, n)
+	for idx, line := range lines[1:] {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) != n+1 {
+			return nil, nil, fmt.Errorf("%s: line %d: %d values for %d species", fileName, idx+2, len(fields)-1, n)
+		}
+		speciesName = append(speciesName, fields[0])
+		row := make([]float64, n)
+		for j, field := range fields[1:] {
+			row[j], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: line %d: wrong format of matrix: %w", fileName, idx+2, err)
+			}
+		}
+		mtx = append(mtx, row)
+	}
+	return mtx, speciesName, nil
+}
+
+//WriteMatrixToFile writes the species names and their distance matrix to a file that ReadMatrixFromFile reads.
+//Whole numbers, like mismatch counts, are written without decimals.
+func WriteMatrixToFile(speciesName []string, matrix [][]float64, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	fmt.Fprintln(w, len(speciesName))
+	for i := range matrix {
+		fmt.Fprint(w, speciesName[i])
+		for j := range matrix[i] {
+			fmt.Fprint(w, "\t", FormatDistance(matrix[i][j]))
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+//FormatDistance writes a distance as a whole number if it is one, with 6 decimals otherwise.
+func FormatDistance(d float64) string {
+	if d == math.Trunc(d) && math.Abs(d) < 1e15 {
+		return strconv.FormatFloat(d, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(d, 'f', 6, 64)
+}
+
+//ReadAlignmentFromFile reads an aligned FASTA file and returns the species names and aligned sequences.
+//A sequence is named by the first word of its header, and every sequence must have the same length.
+func ReadAlignmentFromFile(fileName string) ([]string, []string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	speciesName := make([]string, 0)
+	msa := make([]string, 0)
+	var seq strings.Builder
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line[0] == '>' {
+			if len(speciesName) > 0 {
+				msa = append(msa, seq.String())
+				seq.Reset()
+			}
+			fields := strings.Fields(line[1:])
+			if len(fields) == 0 {
+				return nil, nil, fmt.Errorf("%s: header without a name", fileName)
+			}
+			speciesName = append(speciesName, fields[0])
+			continue
+		}
+		if len(speciesName) == 0 {
+			return nil, nil, fmt.Errorf("%s: sequence before the first '>' header", fileName)
+		}
+		seq.WriteString(strings.ToUpper(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(speciesName) == 0 {
+		return nil, nil, fmt.Errorf("%s: no sequences", fileName)
+	}
+	msa = append(msa, seq.String())
+	if err := CheckAlignment(speciesName, msa); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return speciesName, msa, nil
+}
@@ -0,0 +1,3 @@
+module github.com/zhaoyuanqi/PhylogeneticsAlg
+
+go 1.21
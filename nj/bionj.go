@@ -0,0 +1,70 @@
+package nj
+
+// BIONJ
+// BIONJ (Gascuel 1997) chooses the pairs to join and their branch lengths as neighbor-joining does, but keeps an
+// estimate of the variance of every distance, which grows with the distance. The distance to a new node is a
+// weighted mean of the distances to the two nodes it joins, with the weight lambda that gives it the lowest
+// variance, instead of their plain mean, so long and noisy distances count for less.
+
+// BIONJ takes in distance matrix, species names and returns the BIONJ tree, in the same form as NeighborJoining.
+// The matrix is not changed. It returns an error if CheckMatrix does.
+func BIONJ(mtx Matrix, speciesName []string) (Tree, error) {
+	return joinNeighbors(mtx, speciesName, true)
+}
+
+// Lambda takes in the variance matrix and the chosen col and row, and returns the weight of row in the distances
+// to the new node that minimizes their variance, between 0 and 1.
+func Lambda(v Matrix, col, row int) float64 {
+	n := len(v)
+	if n <= 2 || v[row][col] == 0 {
+		return 0.5
+	}
+	sum := 0.0
+	for k := 0; k < n; k++ {
+		if k != row && k != col {
+			sum += v[col][k] - v[row][k]
+		}
+	}
+	lambda := 0.5 + sum/(2*float64(n-2)*v[row][col])
+	if lambda < 0 {
+		return 0
+	}
+	if lambda > 1 {
+		return 1
+	}
+	return lambda
+}
+
+// AddColRowBIONJ takes in a matrix, the chosen col and row, the weight lambda of row and the branch lengths from the
+// new node to row and col, and returns the matrix with the distances to the new node added as its last row and column.
+func AddColRowBIONJ(mtx Matrix, col, row int, lambda, rowDist, colDist float64) Matrix {
+	n := len(mtx)
+	newRow := make([]float64, n+1)
+	for j := 0; j < n; j++ {
+		if j != row && j != col {
+			newRow[j] = lambda*(mtx[row][j]-rowDist) + (1-lambda)*(mtx[col][j]-colDist)
+		}
+	}
+	mtx = append(mtx, newRow)
+	for i := 0; i < n; i++ {
+		mtx[i] = append(mtx[i], newRow[i])
+	}
+	return mtx
+}
+
+// AddVarianceColRow takes in the variance matrix, the chosen col and row and the weight lambda of row, and returns
+// the matrix with the variances of the distances to the new node added as its last row and column.
+func AddVarianceColRow(v Matrix, col, row int, lambda float64) Matrix {
+	n := len(v)
+	newRow := make([]float64, n+1)
+	for j := 0; j < n; j++ {
+		if j != row && j != col {
+			newRow[j] = lambda*v[row][j] + (1-lambda)*v[col][j] - lambda*(1-lambda)*v[row][col]
+		}
+	}
+	v = append(v, newRow)
+	for i := 0; i < n; i++ {
+		v[i] = append(v[i], newRow[i])
+	}
+	return v
+}
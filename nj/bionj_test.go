@@ -0,0 +1,68 @@
+package nj
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBIONJRecoversAdditiveTree(t *testing.T) {
+	for _, newick := range []string{
+		"(A:1,B:2,C:3);",
+		"((A:2,B:3):4,C:5,(D:1,E:6):2);",
+		"((A:0.5,B:3):1,(C:2,(D:1,E:0.25):0.75):2,(F:4,G:1.5):0.5);",
+	} {
+		mtx, names := additiveMatrix(t, newick)
+		bionj, err := BIONJ(mtx, names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameTree(t, parseTree(t, newick), bionj, names, 1e-12)
+		nj, err := NeighborJoining(mtx, names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameTree(t, nj, bionj, names, 1e-12)
+	}
+}
+
+func TestLambda(t *testing.T) {
+	// the four point sums 5+8, 9+10 and 12+10 differ, so the matrix is not additive
+	v := Matrix{
+		{0, 5, 9, 12},
+		{5, 0, 10, 10},
+		{9, 10, 0, 8},
+		{12, 10, 8, 0},
+	}
+	// 0.5 + ((10-9) + (10-12)) / (2*2*5)
+	if got := Lambda(v, 1, 0); math.Abs(got-0.45) > 1e-12 {
+		t.Errorf("Lambda(v, 1, 0) = %v, want 0.45", got)
+	}
+	if got := Lambda(v, 0, 1); math.Abs(got-0.55) > 1e-12 {
+		t.Errorf("Lambda(v, 0, 1) = %v, want 0.55", got)
+	}
+
+	small := v.Copy()
+	small[0][1], small[1][0] = 0.1, 0.1
+	if got := Lambda(small, 1, 0); got != 0 {
+		t.Errorf("Lambda below 0 is %v, want 0", got)
+	}
+	if got := Lambda(small, 0, 1); got != 1 {
+		t.Errorf("Lambda above 1 is %v, want 1", got)
+	}
+	small[0][1], small[1][0] = 0, 0
+	if got := Lambda(small, 1, 0); got != 0.5 {
+		t.Errorf("Lambda with no variance between the pair is %v, want 0.5", got)
+	}
+
+	// 0.45*9 + 0.55*10 - 0.45*0.55*5 and 0.45*12 + 0.55*10 - 0.45*0.55*5
+	got := AddVarianceColRow(v.Copy(), 1, 0, 0.45)
+	want := []float64{0, 0, 8.3125, 9.6625, 0}
+	if len(got) != 5 {
+		t.Fatalf("%d rows after adding the new node, want 5", len(got))
+	}
+	for j, w := range want {
+		if math.Abs(got[4][j]-w) > 1e-12 || math.Abs(got[j][4]-w) > 1e-12 {
+			t.Errorf("variance between the new node and %d is %v and %v, want %v", j, got[4][j], got[j][4], w)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package nj
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/distance"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/splits"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Bootstrap support
+// The columns of a multiple sequence alignment are resampled with replacement to make replicate alignments of the
+// same length. A tree is built from each replicate with NeighborJoining on the distances of the same model and gap
+// handling as the reference tree, and the support of a branch of the reference tree (built from the original alignment) is the percentage of replicate trees that
+// have the same split of the species into two groups. The splits are found with the splits package on the trees
+// converted with ToTree.
+// Replicate r uses its own random generator seeded with seed+r, so the result does not depend on the number of
+// goroutines or the order in which they finish.
+
+// ResampleColumns returns a replicate alignment made of columns drawn with replacement from msa.
+func ResampleColumns(msa []string, rng *rand.Rand) []string {
+	length := len(msa[0])
+	columns := make([]int, length)
+	for k := range columns {
+		columns[k] = rng.Intn(length)
+	}
+	replicate := make([]string, len(msa))
+	for i, seq := range msa {
+		row := make([]byte, length)
+		for k, col := range columns {
+			row[k] = seq[col]
+		}
+		replicate[i] = string(row)
+	}
+	return replicate
+}
+
+// Bootstrap takes a multiple sequence alignment, the species names and the distance model and gap handling of
+// distance.EvolutionaryDistanceMatrix, builds the reference tree, and returns it with the support of each internal branch, keyed by the label of the node below the branch when the tree is
+// written from its Newick root, and the replicate trees in Newick format. The replicates are spread over the
+// given number of goroutines. It returns an error if the distances or the tree of the alignment or of a replicate
+// can't be computed.
+func Bootstrap(msa, speciesName []string, model, gaps string, replicates, workers int, seed int64) (Tree, map[string]float64, []string, error) {
+	if err := distance.CheckAlignment(speciesName, msa); err != nil {
+		return nil, nil, nil, err
+	}
+	if replicates < 1 {
+		return nil, nil, nil, fmt.Errorf("the number of bootstrap replicates must be positive, not %d", replicates)
+	}
+	if len(msa) > 0 && len(msa[0]) == 0 {
+		return nil, nil, nil, fmt.Errorf("the alignment has no columns to resample")
+	}
+	t, err := bootstrapTree(msa, speciesName, model, gaps)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	reference := t.ToTree()
+	referenceSplits := splits.TreeSplits(reference, splits.TaxaIndex(speciesName), false)
+
+	type result struct {
+		r      int
+		newick string
+		tree   *tree.Tree
+		err    error
+	}
+	jobs := make(chan int)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	if workers < 1 {
+		workers = 1
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				rng := rand.New(rand.NewSource(seed + int64(r)))
+				replicate := ResampleColumns(msa, rng)
+				//a model can fail on a replicate where it didn't on the alignment, when resampling makes two
+				//sequences too different for its correction
+				rt, err := bootstrapTree(replicate, speciesName, model, gaps)
+				if err != nil {
+					results <- result{r: r, err: err}
+					continue
+				}
+				results <- result{r, rt.Newick(), rt.ToTree(), nil}
+			}
+		}()
+	}
+	go func() {
+		for r := 0; r < replicates; r++ {
+			jobs <- r
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	trees := make([]string, replicates)
+	replicateTrees := make([]*tree.Tree, replicates)
+	errs := make([]error, replicates)
+	for res := range results {
+		trees[res.r] = res.newick
+		replicateTrees[res.r] = res.tree
+		errs[res.r] = res.err
+	}
+	for r, err := range errs {
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("bootstrap replicate %d: %w", r+1, err)
+		}
+	}
+	freq := splits.SplitFrequencies(replicateTrees, speciesName, false)
+	support := make(map[string]float64, len(referenceSplits))
+	for split, node := range referenceSplits {
+		support[node.Label] = 100 * freq[split]
+	}
+	return t, support, trees, nil
+}
+
+// bootstrapTree returns the neighbor-joining tree of the distances of an alignment.
+func bootstrapTree(msa, speciesName []string, model, gaps string) (Tree, error) {
+	mtx, err := distance.EvolutionaryDistanceMatrix(speciesName, msa, model, gaps)
+	if err != nil {
+		return nil, err
+	}
+	return NeighborJoining(mtx, speciesName)
+}
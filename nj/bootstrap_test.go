@@ -0,0 +1,92 @@
+package nj
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/distance"
+)
+
+func TestBootstrap(t *testing.T) {
+	// a and b differ from c and d at every fifth column, and each species has a few changes of its own
+	base := strings.Repeat("ACGTA", 40)
+	other := strings.Repeat("ACGTC", 40)
+	msa := []string{"T" + base[1:], "G" + base[1:], other[:199] + "T", other[:199] + "G"}
+	names := []string{"a", "b", "c", "d"}
+
+	tr, support, replicates, err := Bootstrap(msa, names, distance.Count, "", 50, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replicates) != 50 {
+		t.Errorf("%d replicate trees, want 50", len(replicates))
+	}
+	// four species have one internal branch, which splits a and b from c and d in every replicate
+	if len(support) != 1 {
+		t.Fatalf("support of %d branches, want 1: %v", len(support), support)
+	}
+	for label, s := range support {
+		if _, ok := tr.labelIndex()[label]; !ok || !strings.HasPrefix(label, "Internal") {
+			t.Errorf("support keyed by %q, not an internal node of the tree", label)
+		}
+		if s != 100 {
+			t.Errorf("support %v for the split of a and b from c and d, want 100", s)
+		}
+	}
+
+	if _, _, _, err := Bootstrap(msa, names, distance.Count, "", 0, 1, 1); err == nil {
+		t.Error("Bootstrap accepted 0 replicates")
+	}
+}
+
+func TestBootstrapDistanceModel(t *testing.T) {
+	msa := []string{
+		"ACGTACGTACGTTGCAACGT",
+		"ACGTACGAACGTTGCTACGT",
+		"ACGAACGTTCGTAGCAACGA",
+		"TCGAACGTTCCTAGCAACGA",
+	}
+	names := []string{"a", "b", "c", "d"}
+	const seed = 7
+	tr, _, replicates, err := Bootstrap(msa, names, distance.JukesCantor, distance.PairwiseDeletion, 5, 2, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reference tree and every replicate tree are built from Jukes-Cantor distances
+	build := func(msa []string) Tree {
+		mtx, err := distance.EvolutionaryDistanceMatrix(names, msa, distance.JukesCantor, distance.PairwiseDeletion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := NeighborJoining(mtx, names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return want
+	}
+	sameTree(t, build(msa), tr, names, 1e-12)
+	for r, newick := range replicates {
+		want := build(ResampleColumns(msa, rand.New(rand.NewSource(seed+int64(r)))))
+		if newick != want.Newick() {
+			t.Errorf("replicate %d is %s, want %s", r, newick, want.Newick())
+		}
+	}
+
+	if _, _, _, err := Bootstrap(msa, names, "f81", "", 5, 2, seed); err == nil {
+		t.Error("Bootstrap accepted an unknown distance model")
+	}
+}
+
+func TestBootstrapReplicateError(t *testing.T) {
+	// b shares only the first column with the others, so a replicate without it has no sites to compare b with
+	msa := []string{"ACGTACGTAC", "A---------", "ACGTACGTAA", "ACGAACGTAC"}
+	names := []string{"a", "b", "c", "d"}
+	if _, err := distance.EvolutionaryDistanceMatrix(names, msa, distance.JukesCantor, ""); err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err := Bootstrap(msa, names, distance.JukesCantor, "", 50, 3, 1)
+	if err == nil || !strings.Contains(err.Error(), "bootstrap replicate") {
+		t.Errorf("Bootstrap returned %v, want the error of a replicate", err)
+	}
+}
@@ -0,0 +1,5 @@
+// Package nj builds unrooted phylogenetic trees from distance matrices with the neighbor-joining method or its
+// variance-weighted BIONJ variant, and estimates the bootstrap support of their branches from a multiple
+// alignment. FastNeighborJoining builds the same trees as NeighborJoining for thousands of species. UPGMA and
+// WPGMA build rooted ultrametric trees from the same matrices.
+package nj
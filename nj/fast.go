@@ -0,0 +1,217 @@
+package nj
+
+import (
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Fast neighbor-joining
+// FastNeighborJoining makes the same joins as NeighborJoining without building the Q matrix at every step, in the
+// way of RapidNJ (Simonsen, Mailund and Pedersen 2008). The distances live in one flat lower-triangular array of
+// float64, allocated once, where a new node takes over the slot of one of the two nodes it joins. Every node keeps
+// the nodes that were there before it sorted by their distance to it. Since
+//   Q(i,j) = (r-2)*d(i,j) - u(i) - u(j) >= (r-2)*d(i,j) - u(i) - max u,
+// the scan of a sorted row stops as soon as this bound reaches the lowest Q found so far, which is usually after a
+// few entries. The total distances u are updated as nodes come and go instead of being summed again.
+// A row entry packs the distance, rounded down to a float32, above the node number in a uint64, so rows sort as plain
+// integers; the rounded distance is still a lower bound for the rest of the row.
+// Pairs with the same Q are broken as NeighborJoining breaks them, in favor of the lowest node numbers, so both
+// build the same tree up to rounding.
+
+// FastNeighborJoining takes in distance matrix, species names and returns the neighbor-joining tree, in the same
+// form as NeighborJoining. The matrix is not changed. It returns an error if CheckMatrix does.
+func FastNeighborJoining(mtx Matrix, speciesName []string) (Tree, error) {
+	if err := CheckMatrix(mtx, speciesName); err != nil {
+		return nil, err
+	}
+	n := len(speciesName)
+	f := newFastNJ(mtx)
+	t := make(Tree, 2*n-2)
+	for i := range t {
+		t[i] = &NodeList{head: &Node{}}
+		if i < n {
+			t[i].head.label = speciesName[i]
+		}
+	}
+
+	for k := 1; f.r > 2; k++ {
+		a, b := f.closestPair()
+		r := float64(f.r)
+		dab := f.dist(a, b)
+		//the branch lengths are those of AddToTree, a being the row and b the column of the pair
+		da := 0.5 * (dab + (f.u[a]-f.u[b])/(r-2))
+		c := n + k - 1
+		t[c].head.label = "Internal" + strconv.Itoa(k)
+		ConnectNodes(t, c, a, da)
+		ConnectNodes(t, c, b, dab-da)
+		f.join(a, b, c)
+	}
+	//connect the last two nodes, as FinalConnect does
+	x, y := f.active[0], f.active[1]
+	ConnectNodes(t, y, x, f.dist(x, y))
+	return t, nil
+}
+
+// fastNJ holds the state of FastNeighborJoining. Nodes are numbered as in the Tree: the leaves first, then the
+// internal nodes in the order they are made.
+type fastNJ struct {
+	d      []float64  // lower triangle of the distances between slots: slots i > j are at i*(i-1)/2 + j
+	slot   []int      // slot of each node in d, -1 once it is joined
+	u      []float64  // total distance of each node to the active nodes
+	rows   [][]uint64 // nodes made before each node, sorted by their distance to it
+	active []int      // active nodes in increasing order
+	r      int        // number of active nodes
+	dead   int        // entries of rows that point to joined nodes
+}
+
+func newFastNJ(mtx Matrix) *fastNJ {
+	n := len(mtx)
+	f := &fastNJ{
+		d:      make([]float64, n*(n-1)/2),
+		slot:   make([]int, 2*n-1),
+		u:      make([]float64, 2*n-1),
+		rows:   make([][]uint64, 2*n-1),
+		active: make([]int, n, 2*n-1),
+		r:      n,
+	}
+	for i := 0; i < n; i++ {
+		f.slot[i] = i
+		f.active[i] = i
+		for j := 0; j < i; j++ {
+			f.d[i*(i-1)/2+j] = mtx[i][j]
+		}
+	}
+	for i := range f.slot[n:] {
+		f.slot[n+i] = -1
+	}
+	for _, i := range f.active {
+		for _, j := range f.active {
+			if i != j {
+				f.u[i] += f.dist(i, j)
+			}
+		}
+		f.rows[i] = f.sortedRow(i, f.active[:i])
+	}
+	return f
+}
+
+// dist returns the distance between two active nodes.
+func (f *fastNJ) dist(a, b int) float64 {
+	i, j := f.slot[a], f.slot[b]
+	if i < j {
+		i, j = j, i
+	}
+	return f.d[i*(i-1)/2+j]
+}
+
+// sortedRow returns the nodes sorted by their distance to node a, as row entries.
+func (f *fastNJ) sortedRow(a int, nodes []int) []uint64 {
+	row := make([]uint64, len(nodes))
+	for k, b := range nodes {
+		row[k] = rowEntry(f.dist(a, b), b)
+	}
+	slices.Sort(row)
+	return row
+}
+
+// rowEntry packs a distance, rounded down to a float32 and mapped to bits that sort like it, and a node number.
+func rowEntry(d float64, node int) uint64 {
+	d32 := float32(d)
+	if float64(d32) > d {
+		d32 = math.Nextafter32(d32, float32(math.Inf(-1)))
+	}
+	bits := math.Float32bits(d32)
+	if bits&(1<<31) != 0 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 31
+	}
+	return uint64(bits)<<32 | uint64(uint32(node))
+}
+
+// entryBound returns the rounded distance of a row entry, which is at most the distance.
+func entryBound(entry uint64) float64 {
+	bits := uint32(entry >> 32)
+	if bits&(1<<31) != 0 {
+		bits &^= 1 << 31
+	} else {
+		bits = ^bits
+	}
+	return float64(math.Float32frombits(bits))
+}
+
+// closestPair returns the active pair a < b with the lowest Q, the first one in the order of NeighborJoining's
+// scan if several have it.
+func (f *fastNJ) closestPair() (int, int) {
+	r := float64(f.r)
+	uMax := f.u[f.active[0]]
+	for _, a := range f.active {
+		if f.u[a] > uMax {
+			uMax = f.u[a]
+		}
+	}
+	bestA, bestB, best := -1, -1, 0.0
+	for _, a := range f.active {
+		for _, entry := range f.rows[a] {
+			if bestA >= 0 && (r-2)*entryBound(entry)-f.u[a]-uMax > best {
+				break
+			}
+			b := int(uint32(entry))
+			if f.slot[b] < 0 {
+				continue
+			}
+			q := (r-2)*f.dist(a, b) - f.u[a] - f.u[b]
+			//b was made before a, so the pair is (b, a)
+			if bestA < 0 || q < best || q == best && (b < bestA || b == bestA && a < bestB) {
+				bestA, bestB, best = b, a, q
+			}
+		}
+	}
+	return bestA, bestB
+}
+
+// join replaces the active nodes a and b by node c, which takes the slot of a.
+func (f *fastNJ) join(a, b, c int) {
+	dab := f.dist(a, b)
+	slotC := f.slot[a]
+	others := f.active[:0]
+	for _, k := range f.active {
+		if k == a || k == b {
+			continue
+		}
+		dak, dbk := f.dist(a, k), f.dist(b, k)
+		dk := 0.5 * (dak + dbk - dab)
+		f.u[k] += dk - dak - dbk
+		f.u[c] += dk
+		//the slot of a is only written once the distances to a are read
+		i, j := slotC, f.slot[k]
+		if i < j {
+			i, j = j, i
+		}
+		f.d[i*(i-1)/2+j] = dk
+		others = append(others, k)
+	}
+	f.slot[c] = slotC
+	f.slot[a], f.slot[b] = -1, -1
+	f.rows[a], f.rows[b] = nil, nil
+	f.active = append(others, c)
+	f.r--
+	f.rows[c] = f.sortedRow(c, others)
+
+	//up to one entry of each other row pointed to a and to b; once about half of the r*r/2 entries are such dead
+	//entries, drop them
+	f.dead += 2 * f.r
+	if f.dead > f.r*f.r/4 {
+		for _, k := range f.active {
+			row := f.rows[k][:0]
+			for _, entry := range f.rows[k] {
+				if f.slot[uint32(entry)] >= 0 {
+					row = append(row, entry)
+				}
+			}
+			f.rows[k] = row
+		}
+		f.dead = 0
+	}
+}
@@ -0,0 +1,113 @@
+package nj
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/splits"
+)
+
+// randomDistanceMatrix returns the distances between the leaves of a random binary tree with n leaves, each changed
+// by up to 5% so the matrix is close to additive like the distances of real sequences, and the leaf names.
+func randomDistanceMatrix(n int, rng *rand.Rand) (Matrix, []string) {
+	mtx := make(Matrix, n)
+	for i := range mtx {
+		mtx[i] = make([]float64, n)
+	}
+	leaves := rng.Perm(n)
+	// split returns the distance from the root of a random subtree over leaves[lo:hi] to each of its leaves
+	var split func(lo, hi int) []float64
+	split = func(lo, hi int) []float64 {
+		if hi-lo == 1 {
+			return []float64{0}
+		}
+		mid := lo + 1 + rng.Intn(hi-lo-1)
+		left, right := split(lo, mid), split(mid, hi)
+		bl, br := rng.Float64(), rng.Float64()
+		for x := range left {
+			for y := range right {
+				i, j := leaves[lo+x], leaves[mid+y]
+				mtx[i][j] = (left[x] + bl + right[y] + br) * (0.95 + 0.1*rng.Float64())
+				mtx[j][i] = mtx[i][j]
+			}
+		}
+		depths := make([]float64, 0, hi-lo)
+		for _, d := range left {
+			depths = append(depths, d+bl)
+		}
+		for _, d := range right {
+			depths = append(depths, d+br)
+		}
+		return depths
+	}
+	split(0, n)
+	names := make([]string, n)
+	for i := range names {
+		names[i] = "taxon" + strconv.Itoa(i)
+	}
+	return mtx, names
+}
+
+// sameTree fails the test unless the unrooted trees have the same splits with branch lengths that differ by at most
+// tol. Internal node labels are not compared: with four nodes left, both pairs of neighbors have the same Q, so
+// rounding can join them in either order.
+func sameTree(t *testing.T, t1, t2 Tree, speciesName []string, tol float64) {
+	t.Helper()
+	index := splits.TaxaIndex(speciesName)
+	lengths1 := splits.BranchLengths(t1.ToTree(), index, false)
+	lengths2 := splits.BranchLengths(t2.ToTree(), index, false)
+	if len(lengths1) != len(lengths2) {
+		t.Fatalf("%d taxa: %d branches instead of %d", len(speciesName), len(lengths2), len(lengths1))
+	}
+	for split, length := range lengths1 {
+		other, ok := lengths2[split]
+		if !ok {
+			t.Fatalf("%d taxa: no branch %s", len(speciesName), split)
+		}
+		if math.Abs(length-other) > tol*math.Max(1, math.Abs(length)) {
+			t.Fatalf("%d taxa: branch %s has length %v instead of %v", len(speciesName), split, other, length)
+		}
+	}
+}
+
+func TestFastNeighborJoiningMatchesNeighborJoining(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{3, 4, 5, 10, 20, 50, 100, 200} {
+		for rep := 0; rep < 10; rep++ {
+			mtx, names := randomDistanceMatrix(n, rng)
+			slow, err := NeighborJoining(mtx, names)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fast, err := FastNeighborJoining(mtx, names)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameTree(t, slow, fast, names, 1e-9)
+		}
+	}
+}
+
+func BenchmarkNeighborJoining(b *testing.B) {
+	benchmarkTreeBuilding(b, []int{100, 500, 1000}, NeighborJoining)
+}
+
+func BenchmarkFastNeighborJoining(b *testing.B) {
+	benchmarkTreeBuilding(b, []int{100, 500, 1000, 2000, 5000}, FastNeighborJoining)
+}
+
+// benchmarkTreeBuilding times build on a random distance matrix of each size.
+func benchmarkTreeBuilding(b *testing.B, sizes []int, build func(Matrix, []string) (Tree, error)) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range sizes {
+		mtx, names := randomDistanceMatrix(n, rng)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				build(mtx, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package nj
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+//Newick input and output for the unrooted Tree built by NeighborJoining, and conversion to and from the tree package.
+//Nodes in a Tree refer to each other by label, so every label in a tree has to be unique.
+
+// Newick returns the tree in Newick format with branch lengths.
+// The last node in the tree that has more than one neighbor is written as the root,
+// which for a NeighborJoining tree is the last internal node that was created.
+func (t Tree) Newick() string {
+	return t.newick(nil)
+}
+
+// NewickWithSupport returns the tree in Newick format with branch lengths, writing the support of each
+// internal branch (in percent) in place of the label of the node below it, as in bootstrap trees.
+func (t Tree) NewickWithSupport(support map[string]float64) string {
+	return t.newick(support)
+}
+
+// newick writes the tree from its root, with internal node labels or, if support is not nil, support values.
+func (t Tree) newick(support map[string]float64) string {
+	if len(t) == 0 {
+		return ";"
+	}
+	var sb strings.Builder
+	t.writeNewick(&sb, t.labelIndex(), t.NewickRoot(), "", support)
+	sb.WriteString(";")
+	return sb.String()
+}
+
+// NewickRoot returns the index of the node written as the root by Newick: the last node with more than one neighbor.
+func (t Tree) NewickRoot() int {
+	root := len(t) - 1
+	for root > 0 && t.degree(root) < 2 {
+		root--
+	}
+	return root
+}
+
+// writeNewick writes the subtree of node i, reached from the node labelled parent, to sb.
+func (t Tree) writeNewick(sb *strings.Builder, index map[string]int, i int, parent string, support map[string]float64) {
+	children := make([]*Node, 0)
+	for p := t[i].head.next; p != nil; p = p.next {
+		if p.label != parent {
+			children = append(children, p)
+		}
+	}
+	if len(children) > 0 {
+		sb.WriteString("(")
+		for j, child := range children {
+			if j > 0 {
+				sb.WriteString(",")
+			}
+			t.writeNewick(sb, index, index[child.label], t[i].head.label, support)
+			sb.WriteString(":")
+			sb.WriteString(strconv.FormatFloat(child.dist, 'g', -1, 64))
+		}
+		sb.WriteString(")")
+	}
+	if support != nil && len(children) > 0 {
+		if s, ok := support[t[i].head.label]; ok {
+			sb.WriteString(strconv.FormatFloat(s, 'f', 0, 64))
+		}
+		return
+	}
+	sb.WriteString(tree.QuoteLabel(t[i].head.label))
+}
+
+// labelIndex maps the label of each node in the tree to its position in the tree.
+func (t Tree) labelIndex() map[string]int {
+	index := make(map[string]int, len(t))
+	for i := range t {
+		index[t[i].head.label] = i
+	}
+	return index
+}
+
+// degree returns the number of neighbors of node i in the tree.
+func (t Tree) degree(i int) int {
+	n := 0
+	for p := t[i].head.next; p != nil; p = p.next {
+		n++
+	}
+	return n
+}
+
+// WriteNewickToFile writes the tree in Newick format to the given file.
+func WriteNewickToFile(t Tree, fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = fmt.Fprintln(file, t.Newick())
+	return err
+}
+
+// ReadNewickFromFile reads the first Newick tree from the given file.
+func ReadNewickFromFile(fileName string) (Tree, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNewick(string(data))
+}
+
+// ParseNewick turns a Newick string into a Tree.
+func ParseNewick(s string) (Tree, error) {
+	nt, err := tree.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return FromTree(nt)
+}
+
+// FromTree turns a tree of the tree package into a Tree.
+// As in a tree built by NeighborJoining, the leaves come first in the order they appear in the tree,
+// followed by the internal nodes in postorder, so the root is the last node.
+// Internal nodes without a label are named "Internal1", "Internal2", ... in the same order.
+func FromTree(nt *tree.Tree) (Tree, error) {
+	if err := nt.CheckLeaves(); err != nil {
+		return nil, err
+	}
+	nt = nt.Copy()
+	nt.NameInternalNodes("Internal")
+	nodes := nt.LeavesFirst()
+	t := make(Tree, 0, len(nodes))
+	seen := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		if seen[n.Label] {
+			return nil, fmt.Errorf("newick: label %q appears more than once", n.Label)
+		}
+		seen[n.Label] = true
+		var head Node
+		head.label = n.Label
+		t = append(t, &NodeList{head: &head})
+	}
+
+	index := t.labelIndex()
+	for _, n := range nodes {
+		for _, child := range n.Children {
+			ConnectNodes(t, index[n.Label], index[child.Label], child.Length)
+		}
+	}
+	return t, nil
+}
+
+// ToTree returns the tree as an unrooted tree of the tree package, held by the node Newick writes as the root.
+func (t Tree) ToTree() *tree.Tree {
+	if len(t) == 0 {
+		return tree.New(nil, false)
+	}
+	return tree.New(t.toNode(t.labelIndex(), t.NewickRoot(), ""), false)
+}
+
+// toNode returns the subtree of node i, reached from the node labelled parent.
+func (t Tree) toNode(index map[string]int, i int, parent string) *tree.Node {
+	n := tree.NewNode(t[i].head.label)
+	for p := t[i].head.next; p != nil; p = p.next {
+		if p.label != parent {
+			child := t.toNode(index, index[p.label], t[i].head.label)
+			child.SetLength(p.dist)
+			n.AddChild(child)
+		}
+	}
+	return n
+}
+
+// ConnectNodes adds an edge of length dist between nodes i and j of the tree.
+func ConnectNodes(t Tree, i, j int, dist float64) {
+	AppendNeighbor(t[i], t[j].head.label, dist)
+	AppendNeighbor(t[j], t[i].head.label, dist)
+}
+
+// AppendNeighbor adds a node with the given label and distance to the end of a node list.
+func AppendNeighbor(l *NodeList, label string, dist float64) {
+	var newNode Node
+	newNode.label = label
+	newNode.dist = dist
+	p := l.head
+	for p.next != nil {
+		p = p.next
+	}
+	p.next = &newNode
+	l.len++
+}
@@ -0,0 +1,79 @@
+package nj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// additiveMatrix returns the path lengths between the leaves of a Newick tree, in the order of its leaves, and
+// the leaf names.
+func additiveMatrix(t *testing.T, newick string) (Matrix, []string) {
+	nt, err := tree.Parse(newick)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := nt.Leaves()
+	mtx := make(Matrix, len(leaves))
+	names := make([]string, len(leaves))
+	for i, a := range leaves {
+		names[i] = a.Label
+		mtx[i] = make([]float64, len(leaves))
+		for j, b := range leaves {
+			mtx[i][j] = tree.PathLength(a, b)
+		}
+	}
+	return mtx, names
+}
+
+// parseTree returns the Tree of a Newick string.
+func parseTree(t *testing.T, newick string) Tree {
+	tr, err := ParseNewick(newick)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+func TestNewickRoundTrip(t *testing.T) {
+	const want = "((A:2,B:3):4,C:5,(D:1,E:6):2);"
+	mtx, names := additiveMatrix(t, want)
+	tr, err := NeighborJoining(mtx, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := tr.Newick()
+	back := parseTree(t, s)
+	if got := back.Newick(); got != s {
+		t.Errorf("Newick of the parsed tree is %s, want %s", got, s)
+	}
+	sameTree(t, tr, back, names, 0)
+	sameTree(t, parseTree(t, want), back, names, 1e-12)
+}
+
+func TestParseNewick(t *testing.T) {
+	// unlabelled internal nodes are named in postorder, after the leaves
+	tr := parseTree(t, "((A:1,B:2):3,C:4,(D:5,E:6):7);")
+	labels := make([]string, len(tr))
+	for i, n := range tr {
+		labels[i] = n.head.label
+	}
+	if got := strings.Join(labels, " "); got != "A B C D E Internal1 Internal2 Internal3" {
+		t.Errorf("nodes %s, want A B C D E Internal1 Internal2 Internal3", got)
+	}
+	if got := tr.Newick(); got != "((A:1,B:2)Internal1:3,C:4,(D:5,E:6)Internal2:7)Internal3;" {
+		t.Errorf("Newick = %s", got)
+	}
+	// a label already in the tree is skipped
+	tr = parseTree(t, "((A,B)Internal1,C,(D,E));")
+	if got := tr.Newick(); got != "((A:0,B:0)Internal1:0,C:0,(D:0,E:0)Internal2:0)Internal3;" {
+		t.Errorf("Newick = %s", got)
+	}
+
+	for _, s := range []string{"((A,B),A,C);", "((A,B)X,C,(D,E)X);", "((A,B)C,C,D);", "((A,B),C"} {
+		if _, err := ParseNewick(s); err == nil {
+			t.Errorf("ParseNewick accepted %s", s)
+		}
+	}
+}
@@ -1,11 +1,9 @@
-package main
+package nj
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"math"
 	"strconv"
-	"strings"
 )
 
 type Tree []*NodeList
@@ -25,23 +23,23 @@ type TotalDist []float64
 
 type Matrix [][]float64
 
-func main() {
-	fmt.Println("Start construct the tree using Neighbor-Joining method!")
-
-	//First we read a distance matrix and species name from a file
-	fileName := "SpeciesTree.txt"
-	mtx, speciesName := ReadMatrixFromFile(fileName)
-
-	//We then use NeighborJoining to construct the tree
-	t := NeighborJoining(mtx, speciesName)
-
-	//print out the constructed tree
-	t.Print()
-
+// NeighborJoining takes in distance matrix, species names and returns a tree.
+// The matrix is not changed. It returns an error if CheckMatrix does.
+func NeighborJoining(mtx Matrix, speciesName []string) (Tree, error) {
+	return joinNeighbors(mtx, speciesName, false)
 }
 
-// NeighborJoining takes in distance matrix, species names and returns a tree
-func NeighborJoining(mtx Matrix, speciesName []string) Tree {
+// joinNeighbors builds the neighbor-joining tree, or the BIONJ tree if bionj is true.
+func joinNeighbors(mtx Matrix, speciesName []string, bionj bool) (Tree, error) {
+	if err := CheckMatrix(mtx, speciesName); err != nil {
+		return nil, err
+	}
+	mtx = mtx.Copy()
+	//BIONJ keeps the variances of the distances, which start as the distances themselves
+	var v Matrix
+	if bionj {
+		v = mtx.Copy()
+	}
 	leaveLen := len(speciesName)
 	// The initial tree including leavelen Nodelists, each of them is a cluster by themselves
 	t := InitializeTree(speciesName)
@@ -56,12 +54,23 @@ func NeighborJoining(mtx Matrix, speciesName []string) Tree {
 		alteredMtx := AlterMatrix(mtx, u)
 		//Find the minimum elements in the altered matrix
 		row, col, val := FindMinElt(alteredMtx)
+		if row < 0 {
+			return nil, fmt.Errorf("neighbor joining: no pair of clusters to join")
+		}
 
 		//add to tree
 		t = AddToTree(t, i, row, col, val, mtx, k, leaveLen, u, clusters)
 
 		//Add the new created node to the matrix
-		mtx = AddColRow(mtx, col, row)
+		if bionj {
+			lambda := Lambda(v, col, row)
+			rowDist := 0.5 * (mtx[row][col] + Dist(row, col, u))
+			mtx = AddColRowBIONJ(mtx, col, row, lambda, rowDist, mtx[row][col]-rowDist)
+			v = AddVarianceColRow(v, col, row, lambda)
+			v = DelColRow(v, col, row)
+		} else {
+			mtx = AddColRow(mtx, col, row)
+		}
 		//Add Cluster
 		clusters = append(clusters, t[i].head)
 
@@ -78,7 +87,43 @@ func NeighborJoining(mtx Matrix, speciesName []string) Tree {
 	//Final Connect
 	t = FinalConnect(t, mtx, leaveLen, clusters)
 	//t.Print()
-	return t
+	return t, nil
+}
+
+// CheckMatrix returns an error if the distance matrix can't be used to build a tree: there must be at least two
+// species with distinct names, one row per species, and the matrix must be square with finite distances.
+func CheckMatrix(mtx Matrix, speciesName []string) error {
+	if len(speciesName) < 2 {
+		return fmt.Errorf("at least two species are needed to build a tree, got %d", len(speciesName))
+	}
+	if len(mtx) != len(speciesName) {
+		return fmt.Errorf("%d rows in the distance matrix for %d species", len(mtx), len(speciesName))
+	}
+	seen := make(map[string]bool, len(speciesName))
+	for i, name := range speciesName {
+		if seen[name] {
+			return fmt.Errorf("two species are named %q", name)
+		}
+		seen[name] = true
+		if len(mtx[i]) != len(mtx) {
+			return fmt.Errorf("the row of %s has %d distances, not %d", name, len(mtx[i]), len(mtx))
+		}
+		for _, d := range mtx[i] {
+			if math.IsNaN(d) || math.IsInf(d, 0) {
+				return fmt.Errorf("the row of %s has the distance %v", name, d)
+			}
+		}
+	}
+	return nil
+}
+
+// Copy returns a copy of the matrix that doesn't share memory with it.
+func (mtx Matrix) Copy() Matrix {
+	c := make(Matrix, len(mtx))
+	for i := range mtx {
+		c[i] = append([]float64(nil), mtx[i]...)
+	}
+	return c
 }
 
 // InitializeClusters takes in a string slice which contains species names, and
@@ -257,11 +302,11 @@ func AlterMatrix(mtx Matrix, u TotalDist) Matrix {
 }
 
 // FindMinElt takes in the altered matrix that we have and returns the row, col and
-// val of the minimum value
+// val of the minimum value. It returns -1, -1 if the matrix is smaller than 2x2.
 func FindMinElt(alteredMtx Matrix) (int, int, float64) {
 	//	fmt.Println("Finding the Minimum.")
 	if len(alteredMtx) <= 1 || len(alteredMtx[0]) <= 1 {
-		panic("We gave too small a matrix to FindMinElt")
+		return -1, -1, 0
 	}
 	row := 0
 	col := 1
@@ -326,51 +371,6 @@ func DelColRow(mtx Matrix, col, row int) Matrix {
 	return mtx
 }
 
-//ReadMatrixFromFile reads from the file that we take in and returns the matrix,
-// and the species name in the file
-func ReadMatrixFromFile(fileName string) (Matrix, []string) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		fmt.Println("Error: couldn't open the file")
-		os.Exit(1)
-	}
-	lines := make([]string, 0)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if scanner.Err() != nil {
-		fmt.Println("Sorry: there was some kind of error during the file reading")
-		os.Exit(1)
-	}
-	file.Close()
-
-	mtx := make(Matrix, 0)
-	speciesNames := make([]string, 0)
-
-	for idx := range lines {
-		if idx >= 1 {
-			row := make([]float64, 0)
-			nums := strings.Split(lines[idx], "\t")
-			for i, num := range nums {
-				if i == 0 {
-					speciesNames = append(speciesNames, num)
-				} else {
-					n, err := strconv.ParseFloat(num, 64)
-					if err != nil {
-						fmt.Println(err)
-						fmt.Println("Error: Wrong format of matrix!")
-						os.Exit(1)
-					}
-					row = append(row, n)
-				}
-			}
-			mtx = append(mtx, row)
-		}
-	}
-	return mtx, speciesNames
-}
-
 //Print prints out a tree
 func (t Tree) Print() {
 	for i := range t {
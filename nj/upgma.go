@@ -0,0 +1,87 @@
+package nj
+
+import "strconv"
+
+// UPGMA and WPGMA
+// Both methods join the two closest clusters under a new internal node at half their distance, so every leaf is at
+// the same distance from the root and the tree is rooted and ultrametric. They differ in the distance from the new
+// cluster to the others: UPGMA averages it over all the species in the two clusters, WPGMA averages the distances
+// of the two clusters, whatever their sizes.
+// The trees are built in the same Tree as NeighborJoining, with the leaves first and the root last, so they are
+// written with the same Newick functions.
+
+// UPGMA takes in a distance matrix and species names and returns the rooted UPGMA tree with the height of each
+// node, keyed by its label. The matrix is not changed. It returns an error if CheckMatrix does.
+func UPGMA(mtx Matrix, speciesName []string) (Tree, map[string]float64, error) {
+	return AverageLinkage(mtx, speciesName, false)
+}
+
+// WPGMA takes in a distance matrix and species names and returns the rooted WPGMA tree with the height of each
+// node, keyed by its label. The matrix is not changed. It returns an error if CheckMatrix does.
+func WPGMA(mtx Matrix, speciesName []string) (Tree, map[string]float64, error) {
+	return AverageLinkage(mtx, speciesName, true)
+}
+
+// AverageLinkage builds the UPGMA tree, or the WPGMA tree if weighted is true. Internal nodes are named
+// "Internal1", "Internal2", ... in the order they are joined.
+func AverageLinkage(mtx Matrix, speciesName []string, weighted bool) (Tree, map[string]float64, error) {
+	if err := CheckMatrix(mtx, speciesName); err != nil {
+		return nil, nil, err
+	}
+	leaveLen := len(speciesName)
+	//the distances and sizes of the clusters are kept for every node of the tree, and active holds the
+	//nodes that are not joined yet
+	d := make(Matrix, 2*leaveLen-1)
+	for i := range d {
+		d[i] = make([]float64, 2*leaveLen-1)
+		if i < leaveLen {
+			copy(d[i], mtx[i])
+		}
+	}
+	size := make([]int, 2*leaveLen-1)
+	heights := make(map[string]float64, 2*leaveLen-1)
+	t := make(Tree, 0, 2*leaveLen-1)
+	active := make([]int, leaveLen)
+	for i, name := range speciesName {
+		t = append(t, &NodeList{head: &Node{label: name}})
+		size[i] = 1
+		heights[name] = 0
+		active[i] = i
+	}
+
+	for k := 1; len(active) > 1; k++ {
+		//join the closest pair of clusters
+		x, y := 0, 1
+		for a := range active {
+			for b := a + 1; b < len(active); b++ {
+				if d[active[a]][active[b]] < d[active[x]][active[y]] {
+					x, y = a, b
+				}
+			}
+		}
+		i, j := active[x], active[y]
+		n := len(t)
+		label := "Internal" + strconv.Itoa(k)
+		t = append(t, &NodeList{head: &Node{label: label}})
+		heights[label] = d[i][j] / 2
+		ConnectNodes(t, n, i, heights[label]-heights[t[i].head.label])
+		ConnectNodes(t, n, j, heights[label]-heights[t[j].head.label])
+		size[n] = size[i] + size[j]
+
+		for _, m := range active {
+			if m == i || m == j {
+				continue
+			}
+			if weighted {
+				d[n][m] = (d[i][m] + d[j][m]) / 2
+			} else {
+				d[n][m] = (float64(size[i])*d[i][m] + float64(size[j])*d[j][m]) / float64(size[n])
+			}
+			d[m][n] = d[n][m]
+		}
+		active = append(active[:y], active[y+1:]...)
+		active = append(active[:x], active[x+1:]...)
+		active = append(active, n)
+	}
+	return t, heights, nil
+}
@@ -0,0 +1,59 @@
+package nj
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+func TestAverageLinkage(t *testing.T) {
+	// A and B join at height 1 and C joins them at height 3.5. D is 11 from (A,B) and 14 from C: UPGMA weights
+	// (A,B) by its two species, (2*11 + 14) / 3 = 12, WPGMA doesn't, (11 + 14) / 2 = 12.5
+	mtx := Matrix{
+		{0, 2, 6, 10},
+		{2, 0, 8, 12},
+		{6, 8, 0, 14},
+		{10, 12, 14, 0},
+	}
+	names := []string{"A", "B", "C", "D"}
+	for _, tc := range []struct {
+		name  string
+		build func(Matrix, []string) (Tree, map[string]float64, error)
+		root  float64
+	}{
+		{"UPGMA", UPGMA, 6},
+		{"WPGMA", WPGMA, 6.25},
+	} {
+		tr, heights, err := tc.build(mtx, names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]float64{"A": 0, "B": 0, "C": 0, "D": 0, "Internal1": 1, "Internal2": 3.5, "Internal3": tc.root}
+		if len(heights) != len(want) {
+			t.Errorf("%s: %d heights, want %d", tc.name, len(heights), len(want))
+		}
+		for label, h := range want {
+			if got, ok := heights[label]; !ok || math.Abs(got-h) > 1e-12 {
+				t.Errorf("%s: height of %s is %v, want %v", tc.name, label, got, h)
+			}
+		}
+
+		// every leaf is at the height of the root from it
+		nt := tr.ToTree()
+		var root *tree.Node
+		for _, n := range nt.PreOrder() {
+			if n.Label == "Internal3" {
+				root = n
+			}
+		}
+		if root == nil {
+			t.Fatalf("%s: no root Internal3 in %s", tc.name, nt.Newick())
+		}
+		for _, leaf := range nt.Leaves() {
+			if d := tree.PathLength(root, leaf); math.Abs(d-tc.root) > 1e-12 {
+				t.Errorf("%s: %s is %v from the root, want %v", tc.name, leaf.Label, d, tc.root)
+			}
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package parsimony solves the small parsimony problem: it labels the internal nodes of a rooted binary tree with
+// the sequences that minimize the weighted parsimony score of the tree, given the sequences of its leaves.
+package parsimony
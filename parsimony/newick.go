@@ -0,0 +1,148 @@
+package parsimony
+
+import (
+	"fmt"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+//Trees can be read from Newick files with the tree package.
+//An unrooted tree, such as one built by NeighborJoining, is rooted on the branch above its first leaf; with a
+//symmetric score matrix the parsimony score does not depend on where the root is.
+
+//ReadTreeFromFile reads the first tree of a Newick file and returns it with the names of its leaves.
+func ReadTreeFromFile(fileName string) (Tree, []string, error) {
+	nt, err := tree.ReadFile(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return FromTree(nt)
+}
+
+//FromTree turns a tree of the tree package into a Tree laid out with its leaves first and the internal nodes in
+//postorder, and returns it with the names of its leaves. Rooted trees must be binary, unrooted trees are rooted first.
+func FromTree(nt *tree.Tree) (Tree, []string, error) {
+	t, nodes, err := fromTree(nt)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0)
+	for _, n := range nodes {
+		if n.IsLeaf() {
+			names = append(names, n.Label)
+		}
+	}
+	return t, names, nil
+}
+
+//fromTree is FromTree, returning the node of the (rooted copy of the) tree each node of the Tree comes from.
+func fromTree(nt *tree.Tree) (Tree, []*tree.Node, error) {
+	nt = nt.Copy()
+	if !nt.Rooted && len(nt.Root.Children) > 2 {
+		if err := nt.RootAbove(nt.Leaves()[0], 0.5); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := nt.CheckLeaves(); err != nil {
+		return nil, nil, err
+	}
+	t := make(Tree, 0)
+	order := nt.LeavesFirst()
+	nodes := make(map[*tree.Node]*Node)
+	for _, n := range order {
+		node := &Node{}
+		if !n.IsLeaf() && len(n.Children) != 2 {
+			return nil, nil, fmt.Errorf("node %q has %d children, the tree must be binary", n.Label, len(n.Children))
+		} else if !n.IsLeaf() {
+			node.child1 = nodes[n.Children[0]]
+			node.child2 = nodes[n.Children[1]]
+		}
+		nodes[n] = node
+		t = append(t, node)
+	}
+	return t, order, nil
+}
+
+//ToTree returns the tree as a rooted tree of the tree package, with its leaves named by names, as FromTree returns
+//them, and the sequence of each node set by MinimumParsimony as a "sequence" annotation.
+func (t Tree) ToTree(names []string) (*tree.Tree, error) {
+	if len(t) == 0 {
+		return tree.New(nil, true), nil
+	}
+	if len(names) != (len(t)+1)/2 {
+		return nil, fmt.Errorf("%d names for the %d leaves of the tree", len(names), (len(t)+1)/2)
+	}
+	nodes := make(map[*Node]*tree.Node, len(t))
+	for i, node := range t {
+		n := tree.NewNode("")
+		if i < len(names) {
+			n.Label = names[i]
+		}
+		if node.label != "" {
+			n.Annotate("sequence", node.label)
+		}
+		nodes[node] = n
+	}
+	for _, node := range t {
+		for _, child := range []*Node{node.child1, node.child2} {
+			if child != nil {
+				nodes[node].AddChild(nodes[child])
+			}
+		}
+	}
+	return tree.New(nodes[t[len(t)-1]], true), nil
+}
+
+//Ancestors takes a rooted binary tree whose internal nodes all have distinct labels, the sequence of each of its
+//leaves by name, a score matrix and the nucleotides of its rows and columns, and returns the most parsimonious
+//sequence of each internal node by label. A leaf may have an IUPAC ambiguity code where any nucleotide fits, but
+//other letters that are not in the nucList are an error.
+func Ancestors(nt *tree.Tree, seqs map[string]string, mtx Matrix, nucList []string) (map[string]string, error) {
+	if len(nt.Root.Children) > 2 {
+		return nil, fmt.Errorf("the tree must be rooted to label its ancestors")
+	}
+	t, nodes, err := fromTree(nt)
+	if err != nil {
+		return nil, err
+	}
+	leaveseq := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, n := range nodes {
+		if n.IsLeaf() {
+			seq, ok := seqs[n.Label]
+			if !ok {
+				return nil, fmt.Errorf("no sequence for %s", n.Label)
+			}
+			leaveseq = append(leaveseq, seq)
+			continue
+		}
+		if n.Label == "" || seen[n.Label] {
+			return nil, fmt.Errorf("the internal nodes must have distinct labels, found %q", n.Label)
+		}
+		seen[n.Label] = true
+	}
+	if _, err := MinimumParsimony(leaveseq, mtx, t, nucList); err != nil {
+		return nil, err
+	}
+	ancestors := make(map[string]string, len(seen))
+	for i, n := range nodes {
+		if !n.IsLeaf() {
+			ancestors[n.Label] = t[i].label
+		}
+	}
+	return ancestors, nil
+}
+
+//UnitMatrix returns the score matrix of n nucleotides where every change costs 1, the score of Fitch's parsimony.
+func UnitMatrix(n int) Matrix {
+	mtx := make(Matrix, n)
+	for i := range mtx {
+		mtx[i] = make([]float64, n)
+		for j := range mtx[i] {
+			if i != j {
+				mtx[i][j] = 1
+			}
+		}
+	}
+	return mtx
+}
@@ -0,0 +1,75 @@
+package parsimony
+
+import (
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/splits"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+func TestAncestorsAmbiguous(t *testing.T) {
+	nt, err := tree.Parse("((a,b)X,(c,d)Y)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// N fits any nucleotide, so the second position is A everywhere; ties would be broken at random otherwise
+	seqs := map[string]string{"a": "AN", "b": "AA", "c": "AA", "d": "AA"}
+	for run := 0; run < 10; run++ {
+		ancestors, err := Ancestors(nt, seqs, UnitMatrix(len(NucList)), NucList)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, label := range []string{"X", "Y", "R"} {
+			if ancestors[label] != "AA" {
+				t.Fatalf("ancestor %s is %q, want AA", label, ancestors[label])
+			}
+		}
+	}
+}
+
+func TestAncestorsUnknownLetter(t *testing.T) {
+	nt, err := tree.Parse("((a,b)X,(c,d)Y)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqs := map[string]string{"a": "AX", "b": "AA", "c": "AA", "d": "AA"}
+	if _, err := Ancestors(nt, seqs, UnitMatrix(len(NucList)), NucList); err == nil {
+		t.Error("Ancestors accepted the letter X")
+	}
+}
+
+func TestToTree(t *testing.T) {
+	nt, err := tree.Parse("((a,b)X,(c,d)Y)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, names, err := FromTree(nt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaveseq := map[string]string{"a": "A", "b": "A", "c": "C", "d": "C"}
+	seqs := make([]string, len(names))
+	for i, name := range names {
+		seqs[i] = leaveseq[name]
+	}
+	if _, err := MinimumParsimony(seqs, UnitMatrix(len(NucList)), pt, NucList); err != nil {
+		t.Fatal(err)
+	}
+	back, err := pt.ToTree(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the root may be A or C, but the parents of a and b and of c and d are not ties
+	for _, n := range back.Root.Children {
+		want := leaveseq[n.Children[0].Label]
+		if seq, _ := n.Annotation("sequence"); seq != want {
+			t.Errorf("parent of %s has sequence %v, want %s", n.Children[0].Label, seq, want)
+		}
+	}
+	if d, err := splits.TreeDistance(back, nt, splits.RF, true); err != nil || d != 0 {
+		t.Errorf("ToTree gives %s, want the topology of %s (RF %v, %v)", back.Newick(), nt.Newick(), d, err)
+	}
+	if _, err := pt.ToTree(names[1:]); err == nil {
+		t.Error("ToTree accepted too few names")
+	}
+}
@@ -0,0 +1,313 @@
+package parsimony
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//Small Parsimony Problem: Find the most parsimonious labeling of the internal nodes of a rooted tree
+//Input: A rooted binary tree with each leaf labeled by a string of length m
+//Output: A labeling of all other nodes of the tree by strings of length m that minimizes the tree's parsimony score
+
+//Input tree structure
+type Tree []*Node
+
+type Node struct {
+	score                  map[int]float64
+	label                  string //DNA sequence
+	parent, child1, child2 *Node
+}
+
+type Matrix [][]float64
+
+//NucList is the order of the rows and columns of the score matrix in test_dataset.txt.
+var NucList = []string{"A", "T", "C", "G", "-"}
+
+//Ambiguous holds the IUPAC ambiguity codes. A leaf with one of them at a position may have any nucleotide there,
+//so every nucleotide scores 0 at that leaf.
+const Ambiguous = "NRYSWKMBDHV"
+
+//Label returns the sequence of a node, which is set for the internal nodes by MinimumParsimony.
+func (n *Node) Label() string {
+	return n.label
+}
+
+//Score returns the parsimony score of the subtree below a node for each nucleotide (by its index in the nucList)
+//at the last position of the sequences.
+func (n *Node) Score() map[int]float64 {
+	return n.score
+}
+
+//MinimumParsimony assumes that nucleotides in the sequence are independent and seek trees with the lowest possible parsimony score.
+//leaveseq is a slice of string, containing DNA sequences of all leave nodes (present day species).
+//mtx is the score matrix, whose rows and columns are in the order of the nucleotides in nucList.
+func MinimumParsimony(leaveseq []string, mtx Matrix, t Tree, nucList []string) (Tree, error) {
+	if err := CheckInput(leaveseq, mtx, t, nucList); err != nil {
+		return nil, err
+	}
+	InitializeTree(t, leaveseq)
+	//work with one character of each string at a time
+	for i := range leaveseq[0] {
+		BaseMinPars(mtx, t, i, nucList)
+	}
+	return t, nil
+}
+
+//CheckInput returns an error if MinimumParsimony can't label the tree: the tree must have the leaves first and
+//the root last, with two children for every other node, one sequence per leaf, all of the same length and made of
+//nucleotides of the nucList or ambiguity codes, and the score matrix must have a row and a column for each nucleotide.
+func CheckInput(leaveseq []string, mtx Matrix, t Tree, nucList []string) error {
+	if len(leaveseq) < 2 {
+		return fmt.Errorf("at least two leaf sequences are needed, got %d", len(leaveseq))
+	}
+	if len(t) != 2*len(leaveseq)-1 {
+		return fmt.Errorf("a rooted binary tree with %d leaves has %d nodes, not %d", len(leaveseq), 2*len(leaveseq)-1, len(t))
+	}
+	for i := len(leaveseq); i < len(t); i++ {
+		if t[i] == nil || t[i].child1 == nil || t[i].child2 == nil {
+			return fmt.Errorf("internal node %d doesn't have two children", i)
+		}
+	}
+	for i, seq := range leaveseq {
+		if len(seq) != len(leaveseq[0]) {
+			return fmt.Errorf("leaf sequence %d has length %d, not %d", i, len(seq), len(leaveseq[0]))
+		}
+	}
+	if len(nucList) == 0 {
+		return fmt.Errorf("no nucleotides to score")
+	}
+	if len(mtx) != len(nucList) {
+		return fmt.Errorf("the score matrix has %d rows for %d nucleotides", len(mtx), len(nucList))
+	}
+	for i := range mtx {
+		if len(mtx[i]) != len(nucList) {
+			return fmt.Errorf("row %d of the score matrix has %d scores for %d nucleotides", i+1, len(mtx[i]), len(nucList))
+		}
+	}
+	for i, seq := range leaveseq {
+		for j := 0; j < len(seq); j++ {
+			if nucIndex(seq[j:j+1], nucList) < 0 && !strings.Contains(Ambiguous, seq[j:j+1]) {
+				return fmt.Errorf("leaf sequence %d has %q at position %d, which is neither in %v nor an ambiguity code", i, seq[j], j+1, nucList)
+			}
+		}
+	}
+	return nil
+}
+
+//nucIndex returns the index of a nucleotide in the nucList, or -1 if it is not there.
+func nucIndex(nuc string, nucList []string) int {
+	for idx, n := range nucList {
+		if n == nuc {
+			return idx
+		}
+	}
+	return -1
+}
+
+//ReadMatrix takes a parsimony score file as input and store it in a 2-D matrix
+func ReadMatrix(filename string) (Matrix, error) {
+	score := make(Matrix, 0)
+	//Read from file
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		nums := strings.Split(line, "\t")
+		row := make([]float64, 0)
+		for _, num := range nums {
+			//change each string to a float64
+			n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: wrong format of the score matrix: %w", filename, err)
+			}
+			row = append(row, n)
+		}
+		score = append(score, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+//InitializeTree takes a tree and sequences of leave nodes as inputs and adds the label and score to each leave node.
+func InitializeTree(t Tree, leaveseq []string) Tree {
+	leaveLen := len(leaveseq)
+	//set labels for leave nodes as nucleotide sequences
+	for i := 0; i < leaveLen; i++ {
+		t[i].label = leaveseq[i]
+		t[i].score = make(map[int]float64, 5)
+	}
+	//will add labels for root and internal nodes
+	for j := leaveLen; j < len(t); j++ {
+		t[j].label = ""
+		t[j].score = make(map[int]float64, 5)
+	}
+	//Add parent to all the nodes in tree except the root node
+	AddParent(t)
+	return t
+}
+
+//AddParent takes a tree as input and labels every node except root.
+func AddParent(t Tree) Tree {
+	leaveLen := (len(t) + 1) / 2
+	treeLen := len(t)
+	for i := leaveLen; i < treeLen; i++ {
+		node1 := t[i].child1
+		node2 := t[i].child2
+		node1.parent = t[i]
+		node2.parent = t[i]
+	}
+	return t
+}
+
+//BaseMinPars takes in a score matrix, tree, position, and nucList as inputs and returns the minimum parsimony label of internal nodes at this position
+func BaseMinPars(mtx Matrix, t Tree, i int, nucList []string) Tree {
+	//Assign scores for leave nodes
+	InitialScore(t, i, nucList)
+	//Assign scores for internal nodes
+	InternalScore(t, mtx)
+	//Backtrack and find the nucleotide label for internal nodes
+	BackTrack(t, mtx, nucList)
+	return t
+}
+
+//InitialScore takes in a tree, position, and nucList as inputs and returns the initial score map of each node.
+func InitialScore(t Tree, i int, nucList []string) Tree {
+	leaveLen := (len(t) + 1) / 2
+	//set scores of leave nodes equal to 0 for the nucleotide at the current position and infinity for all other nucleotides.
+	//an ambiguity code, which is not in the nucList, scores 0 for all of them.
+	for k := 0; k < leaveLen; k++ {
+		ambiguous := nucIndex(t[k].label[i:i+1], nucList) < 0
+		for idx, nuc := range nucList {
+			if ambiguous || t[k].label[i:i+1] == nuc {
+				t[k].score[idx] = 0.0
+			} else {
+				t[k].score[idx] = math.Inf(1)
+			}
+		}
+	}
+	//set all scores of internal and root nodes to infinity
+	for l := leaveLen; l < len(t); l++ {
+		for idx := range nucList {
+			t[l].score[idx] = math.Inf(1)
+		}
+	}
+	return t
+}
+
+//InternalScore takes a tree and parsimony score matrix as inputs and calculates the score maps for internal and root nodes.
+//The score of nucleotide j at a node is the sum over its children of the lowest cost of the child's subtree with j
+//above it: its score for a nucleotide k plus the cost of changing j into k (Sankoff's algorithm).
+func InternalScore(t Tree, mtx Matrix) Tree {
+	leaveLen := (len(t) + 1) / 2
+	//loop through each internal node
+	for i := leaveLen; i < len(t); i++ {
+		for j := range t[i].score {
+			t[i].score[j] = MinTran(t[i].child1, j, mtx) + MinTran(t[i].child2, j, mtx)
+		}
+	}
+	return t
+}
+
+//FindMin takes a node as input and returns the minimum score (value) in the score map and corresponding key(s)
+func FindMin(node *Node) ([]int, float64) {
+	k := []int{}
+	v := []float64{}
+	label := []int{}
+	for key, value := range node.score {
+		k = append(k, key)
+		v = append(v, value)
+	}
+	minScore := v[0]
+	for i := 1; i < len(v); i++ {
+		if v[i] < minScore {
+			minScore = v[i]
+		}
+	}
+	for idx, val := range v {
+		if val == minScore {
+			label = append(label, k[idx])
+		}
+	}
+	return label, minScore
+}
+
+//MinTran takes a child node, a key of the score map of its parent, and a score matrix and returns the lowest score
+//of the child's subtree when the parent has nucleotide j: the child's score for a nucleotide k plus the cost of j to k.
+func MinTran(child *Node, j int, mtx Matrix) float64 {
+	min := math.Inf(1)
+	for k, score := range child.score {
+		if s := score + mtx[j][k]; s < min {
+			min = s
+		}
+	}
+	return min
+}
+
+//BestChildNuc takes a node, the nucleotide (key) of its parent and a score matrix and returns the nucleotide of the
+//node that gives the parent's score: the one with the lowest score plus cost of the change from the parent.
+//A tie keeps the parent's nucleotide if it is among the best, and the first of them in the nucList otherwise.
+func BestChildNuc(node *Node, parent int, mtx Matrix) int {
+	best := parent
+	min := node.score[parent] + mtx[parent][parent]
+	for k := 0; k < len(node.score); k++ {
+		if s := node.score[k] + mtx[parent][k]; s < min {
+			best, min = k, s
+		}
+	}
+	return best
+}
+
+//BackTrack takes a tree, a score matrix and nucList as inputs and assign labels for all internal and root nodes.
+func BackTrack(t Tree, mtx Matrix, nucList []string) Tree {
+	//Assign the label of root node first
+	root := t[len(t)-1]
+	lr, _ := FindMin(root)
+	if len(lr) == 1 {
+		root.label += nucList[lr[0]]
+	} else {
+		//if root has more than one possible nucleotides which would result in a tree with the same parsimony score, we would randomly choose one from them
+		idx := rand.Intn(len(lr))
+		root.label += nucList[lr[idx]]
+	}
+	//Use the tree with assigned root label to assign labels of internal nodes
+	AddIntNuc(t, root, mtx, nucList)
+	return t
+}
+
+//AddIntNuc takes in a tree, root node, score matrix and nucList as inputs and returns a tree with labeled internal nodes
+//A node is labelled before its children, with the nucleotide that gives the lowest score below its parent's nucleotide.
+func AddIntNuc(t Tree, root *Node, mtx Matrix, nucList []string) {
+	if root == nil {
+		return
+	}
+	//only internal nodes satisfy the three conditions below
+	if root.child1 != nil && root.child2 != nil && root.parent != nil {
+		parentLabel := root.parent.label
+		parent := nucIndex(parentLabel[len(parentLabel)-1:], nucList)
+		root.label += nucList[BestChildNuc(root, parent, mtx)]
+	}
+
+	//Recursion would stop if we reach leave nodes
+	if root.child1 != nil {
+		AddIntNuc(t, root.child1, mtx, nucList)
+	}
+
+	if root.child2 != nil {
+		AddIntNuc(t, root.child2, mtx, nucList)
+	}
+}
@@ -0,0 +1,133 @@
+package parsimony
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+//labellingCost returns the total cost of the changes along the branches of a tree for the given sequence of each node.
+func labellingCost(nt *tree.Tree, seqs map[string]string, mtx Matrix, nucList []string) float64 {
+	cost := 0.0
+	for _, n := range nt.PreOrder() {
+		if n.Parent == nil {
+			continue
+		}
+		for i := range seqs[n.Label] {
+			cost += mtx[nucIndex(seqs[n.Parent.Label][i:i+1], nucList)][nucIndex(seqs[n.Label][i:i+1], nucList)]
+		}
+	}
+	return cost
+}
+
+//bestCost returns the lowest labellingCost over every labelling of the internal nodes, one position at a time.
+func bestCost(nt *tree.Tree, leaves map[string]string, internal []string, mtx Matrix, nucList []string) float64 {
+	total := 0.0
+	for i := range leaves[nt.Leaves()[0].Label] {
+		seqs := make(map[string]string)
+		for name, seq := range leaves {
+			seqs[name] = seq[i : i+1]
+		}
+		best := math.Inf(1)
+		var label func(k int)
+		label = func(k int) {
+			if k == len(internal) {
+				best = math.Min(best, labellingCost(nt, seqs, mtx, nucList))
+				return
+			}
+			for _, nuc := range nucList {
+				seqs[internal[k]] = nuc
+				label(k + 1)
+			}
+		}
+		label(0)
+		total += best
+	}
+	return total
+}
+
+func TestAncestorsWeighted(t *testing.T) {
+	nt, err := tree.Parse("((a,b)X,(c,(d,e)Z)Y)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal := []string{"X", "Y", "Z", "R"}
+	rng := rand.New(rand.NewSource(6))
+	for run := 0; run < 50; run++ {
+		//a symmetric matrix with changes of very different costs
+		mtx := make(Matrix, len(NucList))
+		for i := range mtx {
+			mtx[i] = make([]float64, len(NucList))
+		}
+		for i := range mtx {
+			for j := i + 1; j < len(mtx); j++ {
+				mtx[i][j] = float64(1 + rng.Intn(9))
+				mtx[j][i] = mtx[i][j]
+			}
+		}
+		leaves := make(map[string]string)
+		for _, name := range []string{"a", "b", "c", "d", "e"} {
+			seq := make([]byte, 4)
+			for i := range seq {
+				seq[i] = NucList[rng.Intn(len(NucList))][0]
+			}
+			leaves[name] = string(seq)
+		}
+
+		ancestors, err := Ancestors(nt, leaves, mtx, NucList)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seqs := make(map[string]string)
+		for name, seq := range leaves {
+			seqs[name] = seq
+		}
+		for name, seq := range ancestors {
+			seqs[name] = seq
+		}
+		if got, want := labellingCost(nt, seqs, mtx, NucList), bestCost(nt, leaves, internal, mtx, NucList); got != want {
+			t.Errorf("run %d: ancestors %v cost %v, the most parsimonious labelling %v", run, ancestors, got, want)
+		}
+	}
+}
+
+func TestInternalScoreWeighted(t *testing.T) {
+	//A to C and A to G cost 2, C to G costs 5: the parent X of C and G scores 4 with A and 5 with C or G.
+	//Below R with the leaf C, X is best with C, one more than its lowest score but no change to R
+	nucList := []string{"A", "C", "G"}
+	mtx := Matrix{
+		{0, 2, 2},
+		{2, 0, 5},
+		{2, 5, 0},
+	}
+	nt, err := tree.Parse("((a,b)X,c)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, names, err := FromTree(nt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := map[string]string{"a": "C", "b": "G", "c": "C"}
+	seqs := make([]string, len(names))
+	for i, name := range names {
+		seqs[i] = leaves[name]
+	}
+	if _, err := MinimumParsimony(seqs, mtx, pt, nucList); err != nil {
+		t.Fatal(err)
+	}
+	root := pt[len(pt)-1]
+	want := map[int]float64{0: 6, 1: 5, 2: 10}
+	for k, score := range want {
+		if root.Score()[k] != score {
+			t.Errorf("root scores %v with %s, want %v", root.Score()[k], nucList[k], score)
+		}
+	}
+	for _, n := range pt[len(names):] {
+		if n.Label() != "C" {
+			t.Errorf("internal node has %s, want C", n.Label())
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package reconcile
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Dated reconciliation
+//When the species nodes have divergence times (age, with the present at 0), the species tree is cut into time
+//slices at every node age. A branch exists in every slice between the age of its lower node and the age of its
+//parent, and a transfer is only allowed between two branches that exist in the same slice.
+//A transfer from gene node g placed on species node s to species node x is a transfer from the branch above s
+//to the branch above x.
+
+// Slice is the time interval between two consecutive node ages and the species branches that exist in it,
+// each given by the node below the branch.
+type Slice struct {
+	Start, End float64
+	Branches   []*Node
+}
+
+// ReadAgesFromFile reads divergence times from a file with one "label<TAB>age" line per species node
+// and stores them in the age of the species nodes. Nodes not in the file keep an age of 0.
+func ReadAgesFromFile(fileName string, speciesT Tree) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	nodes := make(map[string]*Node, len(speciesT))
+	for _, s := range speciesT {
+		nodes[s.label] = s
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 2 {
+			return fmt.Errorf("wrong format of age line %q", line)
+		}
+		s, ok := nodes[fields[0]]
+		if !ok {
+			return fmt.Errorf("%q is not a species node", fields[0])
+		}
+		age, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			return fmt.Errorf("wrong age for %q: %v", fields[0], err)
+		}
+		s.age = age
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return CheckAges(speciesT)
+}
+
+// CheckAges takes in a species tree and returns an error unless every node is younger than its parent.
+func CheckAges(speciesT Tree) error {
+	for _, s := range speciesT {
+		if s.parent != nil && s.age >= s.parent.age {
+			return fmt.Errorf("species node %q is not younger than its parent %q", s.label, s.parent.label)
+		}
+	}
+	return nil
+}
+
+// IsDated returns true if the species tree has divergence times, in which case UMPR only allows time-consistent transfers.
+func IsDated(speciesT Tree) bool {
+	return speciesT[len(speciesT)-1].age > 0
+}
+
+// TimeSlices takes in a dated species tree and returns its time slices from the present to the root.
+func TimeSlices(speciesT Tree) []Slice {
+	ages := make([]float64, 0, len(speciesT))
+	seen := make(map[float64]bool, len(speciesT))
+	for _, s := range speciesT {
+		if !seen[s.age] {
+			seen[s.age] = true
+			ages = append(ages, s.age)
+		}
+	}
+	sort.Float64s(ages)
+
+	slices := make([]Slice, 0, len(ages)-1)
+	for i := 0; i+1 < len(ages); i++ {
+		slice := Slice{Start: ages[i], End: ages[i+1]}
+		for _, s := range speciesT {
+			if s.parent != nil && s.age <= slice.Start && slice.End <= s.parent.age {
+				slice.Branches = append(slice.Branches, s)
+			}
+		}
+		slices = append(slices, slice)
+	}
+	return slices
+}
+
+// TimeCompatible takes in two species nodes and returns true if the branches above them exist in the same time slice.
+// Slices are cut at every node age, so this is the case exactly when the two branches overlap in time.
+// In an undated species tree every pair of branches is compatible.
+func TimeCompatible(s, x *Node) bool {
+	if s.parent == nil || x.parent == nil {
+		return false
+	}
+	root := s
+	for root.parent != nil {
+		root = root.parent
+	}
+	if root.age == 0 {
+		return true
+	}
+	return math.Max(s.age, x.age) < math.Min(s.parent.age, x.parent.age)
+}
+
+// ComputeOut takes in the species tree and a gene node whose in and inAlt values are final, and assigns out(g,s):
+// the minimum cost of g on a species node x that can receive a transfer from s.
+func ComputeOut(speciesT Tree, g *Node) {
+	rootNode := speciesT[len(speciesT)-1]
+	if !IsDated(speciesT) {
+		PreOrderIntNSpT(speciesT, rootNode, g)
+		return
+	}
+	for _, s := range speciesT {
+		best := math.Inf(1)
+		// The nodes incomparable to s are the subtrees of the siblings of s and of its ancestors
+		for p := s; p.parent != nil; p = p.parent {
+			sibling := p.parent.child1
+			if sibling == p {
+				sibling = p.parent.child2
+			}
+			best = MinCompatible(g, s, sibling, best)
+		}
+		g.out[s] = best
+	}
+}
+
+// MinCompatible returns the minimum of best and the cost of g on the nodes below x that are time compatible with s.
+func MinCompatible(g, s, x *Node, best float64) float64 {
+	if x == nil || g.inAlt[x] >= best {
+		return best
+	}
+	if g.cost[x] < best && TimeCompatible(s, x) {
+		best = g.cost[x]
+	}
+	best = MinCompatible(g, s, x.child1, best)
+	return MinCompatible(g, s, x.child2, best)
+}
+
+// PrintTimeSlices prints out the branches that exist in each time slice of a dated species tree.
+func PrintTimeSlices(slices []Slice) {
+	for _, slice := range slices {
+		fmt.Print("Slice ", slice.Start, " - ", slice.End, ":")
+		for _, s := range slice.Branches {
+			fmt.Print(" ", s.label)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,148 @@
+package reconcile
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// datedSpecies returns the species tree ((A,B)AB,(C,D)CD)R with the ages R 10, AB 8 and CD 2. The branch above AB
+// exists from 8 to 10 and the branch above C from 0 to 2, so they don't overlap.
+func datedSpecies(t *testing.T) Tree {
+	speciesT, err := ParseNewick("((A,B)AB,(C,D)CD)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ages := map[string]float64{"R": 10, "AB": 8, "CD": 2}
+	for _, s := range speciesT {
+		s.age = ages[s.label]
+	}
+	return speciesT
+}
+
+func TestTimeSlices(t *testing.T) {
+	speciesT := datedSpecies(t)
+	if !IsDated(speciesT) {
+		t.Fatal("IsDated is false for a tree with ages")
+	}
+	want := []struct {
+		start, end float64
+		branches   string
+	}{
+		{0, 2, "A B C D"},
+		{2, 8, "A B CD"},
+		{8, 10, "AB CD"},
+	}
+	slices := TimeSlices(speciesT)
+	if len(slices) != len(want) {
+		t.Fatalf("%d time slices, want %d", len(slices), len(want))
+	}
+	for i, w := range want {
+		labels := ""
+		for j, s := range slices[i].Branches {
+			if j > 0 {
+				labels += " "
+			}
+			labels += s.label
+		}
+		if slices[i].Start != w.start || slices[i].End != w.end || labels != w.branches {
+			t.Errorf("slice %d is %v-%v with %s, want %v-%v with %s", i, slices[i].Start, slices[i].End, labels, w.start, w.end, w.branches)
+		}
+	}
+
+	if TimeCompatible(nodeByLabel(t, speciesT, "AB"), nodeByLabel(t, speciesT, "C")) {
+		t.Error("the branches above AB and C are time compatible")
+	}
+	if !TimeCompatible(nodeByLabel(t, speciesT, "AB"), nodeByLabel(t, speciesT, "CD")) {
+		t.Error("the branches above AB and CD are not time compatible")
+	}
+
+	if err := CheckAges(speciesT); err != nil {
+		t.Error(err)
+	}
+	nodeByLabel(t, speciesT, "AB").age = 12
+	if err := CheckAges(speciesT); err == nil {
+		t.Error("CheckAges accepted AB older than its parent")
+	}
+	nodeByLabel(t, speciesT, "AB").age = 10
+	if err := CheckAges(speciesT); err == nil {
+		t.Error("CheckAges accepted AB as old as its parent")
+	}
+}
+
+func TestDatedTransfer(t *testing.T) {
+	// C goes with A and B: the cheapest reconciliation transfers C from the branch above AB to the branch above C,
+	// which a dated tree doesn't allow, leaving a speciation at R with the loss of D. Transfers cost 2 so that moving
+	// (A,B) from the branch above C, which is time compatible, costs more than the loss
+	const loss, dup, transfer = 3, 3, 2
+	for _, dated := range []bool{false, true} {
+		geneT, err := ParseNewick("((A,B),C);")
+		if err != nil {
+			t.Fatal(err)
+		}
+		speciesT := datedSpecies(t)
+		if !dated {
+			speciesT, err = ParseNewick("((A,B)AB,(C,D)CD)R;")
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		cost, err := UMPR(geneT, speciesT, len(geneT), len(speciesT), loss, dup, transfer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sc, err := Traceback(geneT, speciesT, loss)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 2.0
+		if dated {
+			want = 3
+		}
+		if cost != want {
+			t.Errorf("dated %v: cost %v, want %v", dated, cost, want)
+		}
+		if dated != (sc.Transfers == 0) {
+			t.Errorf("dated %v: %d transfers", dated, sc.Transfers)
+		}
+		// undated, the gene root can as well sit on C and transfer (A,B) to AB
+		for _, m := range sc.Mappings {
+			pair := NodeLabel(m.Donor) + " " + NodeLabel(m.Recipient)
+			if m.Event == "transfer" && pair != "AB C" && pair != "C AB" {
+				t.Errorf("transfer from %s to %s, want between AB and C", NodeLabel(m.Donor), NodeLabel(m.Recipient))
+			}
+		}
+	}
+}
+
+func TestDatedTracebackIsTimeConsistent(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for i := 0; i < 300; i++ {
+		geneT, speciesT := randomTrees(t, rng)
+		// every internal node is older than its children; the nodes are in postorder
+		for _, s := range speciesT {
+			if s.child1 != nil {
+				s.age = s.child1.age
+				if s.child2.age > s.age {
+					s.age = s.child2.age
+				}
+				s.age += 1 + float64(rng.Intn(3))
+			}
+		}
+		if err := CheckAges(speciesT); err != nil {
+			t.Fatal(err)
+		}
+		loss, dup, transfer := 1+rng.Intn(4), 1+rng.Intn(4), 1+rng.Intn(4)
+		if _, err := UMPR(geneT, speciesT, len(geneT), len(speciesT), loss, dup, transfer); err != nil {
+			t.Fatal(err)
+		}
+		sc, err := Traceback(geneT, speciesT, loss)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range sc.Mappings {
+			if m.Event == "transfer" && !TimeCompatible(m.Donor, m.Recipient) {
+				t.Errorf("case %d: transfer from %s to %s, which don't overlap in time", i, NodeLabel(m.Donor), NodeLabel(m.Recipient))
+			}
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package reconcile reconciles gene trees with species trees. LabelEvents maps each gene tree node to the last
+// common ancestor of its species and labels it as a duplication or a speciation. UMPR finds the minimum cost of a
+// reconciliation with duplications, transfers and losses, and Traceback, CountScenarios, SampleScenario and
+// EventSupports rebuild, count, draw and summarize the reconciliations with that cost. RootByDuplications and
+// RootByUMPR root an unrooted gene tree on the branch that reconciles best with the species tree.
+package reconcile
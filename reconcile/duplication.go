@@ -0,0 +1,118 @@
+package reconcile
+
+import "fmt"
+
+//Infer gene duplication and speciation events on a gene tree by refering to a species tree
+//Input: Rooted binary gene tree G, rooted binary species tree S of all species in G.
+//Output: G with "duplication" or "speciation" assigned to each of its internal nodes
+//Each species tree node gets a number in preorder, so an ancestor always has a lower number than its descendants.
+//An internal gene tree node maps to the last common ancestor (LCA) of the species its children map to; it is a
+//duplication if it maps to the same species node as one of its children, and a speciation otherwise.
+
+//LabelEvents takes a gene tree and a species tree, checks them, and labels every internal node of the gene tree
+//with "duplication" or "speciation". The gene tree leaves are matched to the species tree leaves by label.
+func LabelEvents(gTree, sTree Tree) error {
+	if err := CheckTree(gTree); err != nil {
+		return fmt.Errorf("gene tree: %w", err)
+	}
+	if err := CheckTree(sTree); err != nil {
+		return fmt.Errorf("species tree: %w", err)
+	}
+	speciesnum := LeafNum(sTree)
+	if err := CheckLeaves(gTree, sTree, speciesnum); err != nil {
+		return err
+	}
+	LabelInternalNodeEvent(gTree, sTree, sTree[len(sTree)-1], speciesnum)
+	return nil
+}
+
+//CheckLeaves takes a gene tree, a species tree and the number of species, and checks that every leaf of the gene tree
+//is labelled with the name of a leaf of the species tree.
+func CheckLeaves(gTree, sTree Tree, speciesnum int) error {
+	species := make(map[string]bool, speciesnum)
+	for j := 0; j < speciesnum; j++ {
+		species[sTree[j].label] = true
+	}
+	for i := 0; i < LeafNum(gTree); i++ {
+		if !species[gTree[i].label] {
+			return fmt.Errorf("gene tree leaf %q is not a species in the species tree", gTree[i].label)
+		}
+	}
+	return nil
+}
+
+//LabelInternalNodes takes in a gene tree, a species tree, a root node and the number of species, and labels the internal nodes of the gene tree with event.
+func LabelInternalNodeEvent(gTree, sTree Tree, root *Node, speciesnum int) {
+	//Initialize species tree.
+	InitializeSTree(sTree, root, 1)
+	//Initialize gene tree.
+	InitializeGTree(gTree, sTree, speciesnum)
+	//Traverse the internal nodes in the gene tree and label them with either speciation or duplication event.
+	TraverseGTree(gTree, sTree, speciesnum)
+}
+
+//InitializeSTree takes a species tree, a node and its number as inputs, and recursively numbers the nodes of its
+//subtree in preorder traversal. It returns the number after the last one it used.
+//root = 1, child nodes always larger than parent node
+func InitializeSTree(t Tree, root *Node, number int) int {
+	root.number = number
+	number++
+	if root.child1 != nil {
+		number = InitializeSTree(t, root.child1, number)
+	}
+	if root.child2 != nil {
+		number = InitializeSTree(t, root.child2, number)
+	}
+	return number
+}
+
+//InitializeGTree takes a gene tree, a species tree, and the number of species as input.
+//For each leave node in the gene tree, set its label to the number of the leave node in species tree with the matching species name.
+//The gene tree may have more leaves than there are species, when a species carries more than one copy of the gene.
+func InitializeGTree(gTree, sTree Tree, speciesnum int) Tree {
+	for i := 0; i < LeafNum(gTree); i++ {
+		for j := 0; j < speciesnum; j++ {
+			if gTree[i].label == sTree[j].label {
+				gTree[i].number = sTree[j].number
+			}
+		}
+	}
+	return gTree
+}
+
+//TraverseGTree takes a gene tree, a species tree and the species number as input, and assignes "duplication" or "speciation" to internal nodes of gene tree
+func TraverseGTree(gTree, sTree Tree, speciesnum int) {
+	for i := LeafNum(gTree); i < len(gTree); i++ {
+		node := gTree[i]
+		a := node.child1.number
+		b := node.child2.number
+		for a != b {
+			if a > b {
+				//parent of node a in the species tree
+				a = FindParentNum(sTree, a)
+			} else {
+				b = FindParentNum(sTree, b)
+			}
+		}
+		//node.number cannot be lower (greater) than node.child1.number or node.child2.number
+		//node.number is the Last Common Ancestor (LCA) of node.child1.number and node.child2.number
+		node.number = a
+		if node.number == node.child1.number || node.number == node.child2.number {
+			node.event = "duplication"
+		} else {
+			node.event = "speciation"
+		}
+	}
+}
+
+//FindParentNum takes a species tree and a node number as input, and looks for the node with the same node number in the species tree.
+//It returns the parent node number of the node with number a, or the number of the root, 1, if a is the root or
+//is not in the tree.
+func FindParentNum(sTree Tree, a int) int {
+	for _, node := range sTree {
+		if node.number == a && node.parent != nil {
+			return node.parent.number
+		}
+	}
+	return 1
+}
@@ -0,0 +1,50 @@
+package reconcile
+
+import "testing"
+
+func TestLabelEvents(t *testing.T) {
+	geneT, err := ParseNewick("((A,B),(A,C));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	speciesT, err := ParseNewick("((A,B)AB,C)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LabelEvents(geneT, speciesT); err != nil {
+		t.Fatal(err)
+	}
+	// (A,B) maps to AB and (A,C) to R, both speciations; the root maps to R like (A,C), a duplication
+	want := "((A,B)S[&&NHX:S=AB:D=N],(A,C)S[&&NHX:S=R:D=N])D[&&NHX:S=R:D=Y];"
+	if got := EventNewick(geneT, speciesT); got != want {
+		t.Errorf("EventNewick = %s, want %s", got, want)
+	}
+	if got := Duplications(geneT); got != 1 {
+		t.Errorf("%d duplications, want 1", got)
+	}
+
+	// unnamed species nodes are written with underscores for spaces, and branch lengths are kept
+	geneT, err = ParseNewick("((A:1,B:2):3,C:4);")
+	if err != nil {
+		t.Fatal(err)
+	}
+	speciesT, err = ParseNewick("((A,B),C);")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LabelEvents(geneT, speciesT); err != nil {
+		t.Fatal(err)
+	}
+	want = "((A:1,B:2)S[&&NHX:S=Ancestor_Species_1:D=N]:3,C:4)S[&&NHX:S=Ancestor_Species_2:D=N];"
+	if got := EventNewick(geneT, speciesT); got != want {
+		t.Errorf("EventNewick = %s, want %s", got, want)
+	}
+
+	geneT, err = ParseNewick("((A,D),C);")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LabelEvents(geneT, speciesT); err == nil {
+		t.Error("LabelEvents accepted the gene leaf D, which is not a species")
+	}
+}
@@ -0,0 +1,73 @@
+package reconcile
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+//Newick output of a gene tree labelled by LabelEvents.
+
+// LeafNum returns the number of leaves in a tree laid out with its leaves first.
+func LeafNum(t Tree) int {
+	n := 0
+	for n < len(t) && t[n].child1 == nil {
+		n++
+	}
+	return n
+}
+
+// WriteNewickToFile writes the gene tree in Newick format to the given file, with its events on the internal nodes.
+func WriteNewickToFile(gTree, sTree Tree, fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = fmt.Fprintln(file, EventNewick(gTree, sTree))
+	return err
+}
+
+// EventNewick returns the gene tree in Newick format after LabelInternalNodeEvent has been run.
+// Each internal node is labelled "D" for duplication or "S" for speciation and carries an NHX comment
+// with the same event and the species tree node it maps to, e.g. D[&&NHX:S=Ancestor_Species_1:D=Y].
+func EventNewick(gTree, sTree Tree) string {
+	species := make(map[int]string, len(sTree))
+	for _, node := range sTree {
+		species[node.number] = node.label
+	}
+	var sb strings.Builder
+	WriteEventNode(&sb, gTree[len(gTree)-1], species)
+	sb.WriteString(";")
+	return sb.String()
+}
+
+// WriteEventNode writes the subtree of node to sb.
+func WriteEventNode(sb *strings.Builder, node *Node, species map[int]string) {
+	if node.child1 == nil {
+		sb.WriteString(tree.QuoteLabel(node.label))
+	} else {
+		sb.WriteString("(")
+		WriteEventNode(sb, node.child1, species)
+		sb.WriteString(",")
+		WriteEventNode(sb, node.child2, species)
+		sb.WriteString(")")
+		dup := "N"
+		if node.event == "duplication" {
+			sb.WriteString("D")
+			dup = "Y"
+		} else {
+			sb.WriteString("S")
+		}
+		//NHX values can't contain brackets, colons or spaces
+		name := strings.NewReplacer("[", "", "]", "", ":", "", " ", "_").Replace(species[node.number])
+		sb.WriteString("[&&NHX:S=" + name + ":D=" + dup + "]")
+	}
+	if node.parent != nil && node.hasDist {
+		sb.WriteString(":")
+		sb.WriteString(strconv.FormatFloat(node.dist, 'g', -1, 64))
+	}
+}
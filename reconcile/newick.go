@@ -0,0 +1,110 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Gene and species trees can be read from rooted binary Newick files with the tree package. The nodes are laid out
+// the way UMPR and LabelEvents rely on: the leaves first in the order they appear in the string, then the internal
+// nodes in postorder, so the root is the last node of the tree.
+
+// ReadNewickFromFile reads the first tree of a Newick file.
+func ReadNewickFromFile(fileName string) (Tree, error) {
+	nt, err := tree.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return FromTree(nt)
+}
+
+// ParseNewick turns a rooted binary Newick tree into a Tree with parent pointers set.
+func ParseNewick(s string) (Tree, error) {
+	nt, err := tree.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return FromTree(nt)
+}
+
+// FromTree turns a rooted binary tree of the tree package into a Tree with parent pointers set.
+// Internal nodes without a label are named "Ancestor Species 1", "Ancestor Species 2", ... in postorder.
+func FromTree(nt *tree.Tree) (Tree, error) {
+	nt = nt.Copy()
+	nt.NameInternalNodes("Ancestor Species ")
+	t := make(Tree, 0)
+	nodes := make(map[*tree.Node]*Node)
+	for _, n := range nt.LeavesFirst() {
+		if n.IsLeaf() && n.Label == "" {
+			return nil, errors.New("newick: leaf without a label")
+		}
+		if !n.IsLeaf() && len(n.Children) != 2 {
+			return nil, fmt.Errorf("newick: node %q has %d children, the tree must be rooted and binary", n.Label, len(n.Children))
+		}
+		node := &Node{label: n.Label, dist: n.Length, hasDist: n.HasLength}
+		if !n.IsLeaf() {
+			node.child1 = nodes[n.Children[0]]
+			node.child2 = nodes[n.Children[1]]
+			node.child1.parent = node
+			node.child2.parent = node
+		}
+		nodes[n] = node
+		t = append(t, node)
+	}
+	return t, nil
+}
+
+// ToTree returns the tree as a rooted tree of the tree package, with the branch lengths it was read with and the
+// event of each internal node set by LabelEvents or Traceback as an "event" annotation.
+func (t Tree) ToTree() *tree.Tree {
+	if len(t) == 0 {
+		return tree.New(nil, true)
+	}
+	return tree.New(toNode(t[len(t)-1]), true)
+}
+
+// toNode returns the subtree of node as nodes of the tree package.
+func toNode(node *Node) *tree.Node {
+	n := tree.NewNode(node.label)
+	if node.hasDist {
+		n.SetLength(node.dist)
+	}
+	if node.event != "" {
+		n.Annotate("event", node.event)
+	}
+	for _, child := range []*Node{node.child1, node.child2} {
+		if child != nil {
+			n.AddChild(toNode(child))
+		}
+	}
+	return n
+}
+
+// CheckTree returns an error if a tree is not laid out as FromTree lays it out: a rooted binary tree with its
+// leaves first, then its internal nodes, and its root last.
+func CheckTree(t Tree) error {
+	if len(t) == 0 {
+		return errors.New("empty tree")
+	}
+	leaves := LeafNum(t)
+	if len(t) != 2*leaves-1 {
+		return fmt.Errorf("a rooted binary tree with %d leaves has %d nodes, not %d", leaves, 2*leaves-1, len(t))
+	}
+	for i, node := range t {
+		if node == nil {
+			return fmt.Errorf("node %d is missing", i)
+		}
+		if (node.child1 == nil) != (node.child2 == nil) {
+			return fmt.Errorf("node %q has one child, the tree must be binary", node.label)
+		}
+		if i >= leaves && node.child1 == nil {
+			return fmt.Errorf("leaf %q comes after the internal nodes", node.label)
+		}
+		if (node.parent == nil) != (i == len(t)-1) {
+			return fmt.Errorf("node %q and only it should be the root", node.label)
+		}
+	}
+	return nil
+}
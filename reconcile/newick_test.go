@@ -0,0 +1,39 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/splits"
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+func TestToTree(t *testing.T) {
+	newicks := []string{"((A:1,B:2)AB:1,(C,D)CD)R;", "((A,B),(C,D));", "((A,C),(B,D));"}
+	trees := make([]*tree.Tree, len(newicks))
+	for i, s := range newicks {
+		rt, err := ParseNewick(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trees[i] = rt.ToTree()
+	}
+	if got := trees[0].Newick(); got != newicks[0] {
+		t.Errorf("ToTree written as %s, want %s", got, newicks[0])
+	}
+
+	// two of the three trees split A and B from C and D
+	consensus, err := splits.Consensus(trees, splits.Majority, 0.5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := tree.Parse("((A,B),(C,D));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := splits.TreeDistance(consensus, want, splits.RF, true); err != nil || d != 0 {
+		t.Errorf("majority consensus %s, want %s (RF %v, %v)", consensus.Newick(), want.Newick(), d, err)
+	}
+	if d, err := splits.TreeDistance(trees[1], trees[2], splits.RF, true); err != nil || d != 4 {
+		t.Errorf("rooted RF between %s and %s is %v (%v), want 4", newicks[1], newicks[2], d, err)
+	}
+}
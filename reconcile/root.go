@@ -0,0 +1,82 @@
+package reconcile
+
+import (
+	"errors"
+	"math"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Rooting a gene tree by reconciliation
+// An unrooted gene tree, such as a neighbor-joining tree, is rooted on each of its branches in turn and every rooting
+// is reconciled with the species tree; the rooting that needs the fewest duplications, or has the lowest U-MPR cost,
+// is kept. The species of a gene tree leaf is given by a map from its label, or is the label itself.
+
+// RootByDuplications returns the rooting of the gene tree with the fewest duplications after LabelEvents, and that
+// number of duplications. Ties keep the first rooting in the order of tree.Rootings.
+func RootByDuplications(geneTree *tree.Tree, speciesT Tree, species map[string]string) (*tree.Tree, int, error) {
+	best, fewest, err := BestRooting(geneTree, species, func(geneT Tree) (float64, error) {
+		if err := LabelEvents(geneT, speciesT); err != nil {
+			return 0, err
+		}
+		return float64(Duplications(geneT)), nil
+	})
+	return best, int(fewest), err
+}
+
+// RootByUMPR returns the rooting of the gene tree with the lowest U-MPR cost for the given costs of a loss, a
+// duplication and a transfer, and that cost.
+func RootByUMPR(geneTree *tree.Tree, speciesT Tree, species map[string]string, PLoss, Pduplication, Ptransfer int) (*tree.Tree, float64, error) {
+	return BestRooting(geneTree, species, func(geneT Tree) (float64, error) {
+		return UMPR(geneT, speciesT, len(geneT), len(speciesT), PLoss, Pduplication, Ptransfer)
+	})
+}
+
+// BestRooting reconciles every rooting of the gene tree with cost, its leaves renamed after their species, and
+// returns the rooting with the lowest cost, with the gene tree's own leaf labels, and that cost. It returns an error
+// if the gene tree has no branch or if every rooting has an infinite cost.
+func BestRooting(geneTree *tree.Tree, species map[string]string, cost func(geneT Tree) (float64, error)) (*tree.Tree, float64, error) {
+	rootings, err := geneTree.Rootings()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(rootings) == 0 {
+		return nil, 0, errors.New("the gene tree has no branch to root on")
+	}
+	var best *tree.Tree
+	lowest := math.Inf(1)
+	for _, rooted := range rootings {
+		renamed := rooted.Copy()
+		for _, leaf := range renamed.Leaves() {
+			if name, ok := species[leaf.Label]; ok {
+				leaf.Label = name
+			}
+		}
+		geneT, err := FromTree(renamed)
+		if err != nil {
+			return nil, 0, err
+		}
+		c, err := cost(geneT)
+		if err != nil {
+			return nil, 0, err
+		}
+		if c < lowest {
+			best, lowest = rooted, c
+		}
+	}
+	if best == nil {
+		return nil, 0, errors.New("every rooting of the gene tree has an infinite cost")
+	}
+	return best, lowest, nil
+}
+
+// Duplications returns the number of internal nodes of a gene tree labelled as duplications.
+func Duplications(gTree Tree) int {
+	duplications := 0
+	for _, node := range gTree {
+		if node.event == "duplication" {
+			duplications++
+		}
+	}
+	return duplications
+}
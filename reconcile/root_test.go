@@ -0,0 +1,100 @@
+package reconcile
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// rootSplit returns the sorted leaf labels below each child of the root, joined by commas, with the two sides in
+// sorted order separated by '|'.
+func rootSplit(t *tree.Tree) string {
+	var leaves func(n *tree.Node) []string
+	leaves = func(n *tree.Node) []string {
+		if n.IsLeaf() {
+			return []string{n.Label}
+		}
+		labels := []string{}
+		for _, c := range n.Children {
+			labels = append(labels, leaves(c)...)
+		}
+		return labels
+	}
+	sides := make([]string, len(t.Root.Children))
+	for i, c := range t.Root.Children {
+		labels := leaves(c)
+		sort.Strings(labels)
+		sides[i] = strings.Join(labels, ",")
+	}
+	sort.Strings(sides)
+	return strings.Join(sides, "|")
+}
+
+func TestRootByReconciliation(t *testing.T) {
+	speciesT, err := ParseNewick("((A,B),(C,D));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	species := map[string]string{"a1": "A", "b1": "B", "c1": "C", "d1": "D"}
+	// rooted on a1, the gene tree needs a duplication at its root; rooted between (a1,b1) and (c1,d1), it needs none
+	geneTree, err := tree.Parse("(a1,(b1,(c1,d1)));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong, err := FromTree(geneTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaf := range wrong[:LeafNum(wrong)] {
+		leaf.label = species[leaf.label]
+	}
+	if err := LabelEvents(wrong, speciesT); err != nil {
+		t.Fatal(err)
+	}
+	if d := Duplications(wrong); d != 1 {
+		t.Fatalf("the gene tree rooted on a1 has %d duplications, want 1", d)
+	}
+
+	rooted, duplications, err := RootByDuplications(geneTree, speciesT, species)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duplications != 0 || rootSplit(rooted) != "a1,b1|c1,d1" {
+		t.Errorf("rooted as %s with %d duplications, want the root between a1,b1 and c1,d1 with none", rooted.Newick(), duplications)
+	}
+
+	rooted, cost, err := RootByUMPR(geneTree, speciesT, species, 1, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 0 || rootSplit(rooted) != "a1,b1|c1,d1" {
+		t.Errorf("rooted as %s with cost %v, want the root between a1,b1 and c1,d1 with cost 0", rooted.Newick(), cost)
+	}
+}
+
+func TestBestRootingErrors(t *testing.T) {
+	geneTree, err := tree.Parse("((a,b),(c,d));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = BestRooting(geneTree, nil, func(geneT Tree) (float64, error) {
+		return math.Inf(1), nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "infinite") {
+		t.Errorf("BestRooting with infinite costs returned %v", err)
+	}
+
+	leaf, err := tree.Parse("a;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = BestRooting(leaf, nil, func(geneT Tree) (float64, error) {
+		return 0, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "no branch") {
+		t.Errorf("BestRooting of a single leaf returned %v", err)
+	}
+}
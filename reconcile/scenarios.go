@@ -0,0 +1,367 @@
+package reconcile
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+)
+
+//All optimal reconciliations
+//A reconciliation is fixed by the species node, event and, for a transfer, the transferred child and recipient
+//of every gene node. Each gene node g placed on species node s can have several choices with the optimal cost
+//g.cost[s]. count(g,s) is the number of optimal reconciliations of the subtree of g with g placed on s, and
+//outside(g,s) is the number of ways to complete the rest of the gene tree around it.
+
+// Placement is a gene node placed on a species node after a number of losses on the edge above it.
+type Placement struct {
+	Gene, Species *Node
+	Losses        int
+}
+
+// Choice is one way of placing the two children of a gene node at the cost in the DP tables.
+type Choice struct {
+	Mapping  Mapping
+	Children [2]Placement
+}
+
+// EventSupport is the number and fraction of optimal reconciliations that contain one event.
+type EventSupport struct {
+	Gene, Species *Node
+	Event         string
+	Recipient     *Node // for transfers
+	Count         *big.Int
+	Frequency     float64
+}
+
+// RootPlacements takes in the gene tree and the species tree after UMPR and returns every species node
+// the gene root can be placed on with the optimal cost.
+func RootPlacements(geneT, speciesT Tree) []Placement {
+	g := geneT[len(geneT)-1]
+	opt := OptimalCost(g, speciesT)
+	roots := make([]Placement, 0)
+	for _, s := range speciesT {
+		if g.cost[s] == opt {
+			roots = append(roots, Placement{Gene: g, Species: s})
+		}
+	}
+	return roots
+}
+
+// Choices takes in an internal gene node g placed on species node s and the cost of a loss, and
+// returns every speciation, duplication and transfer that gives g.cost[s].
+func Choices(g, s *Node, loss float64) []Choice {
+	choices := make([]Choice, 0)
+	g1 := g.child1
+	g2 := g.child2
+	opt := g.cost[s]
+
+	if g.speciation[s] == opt && s.child1 != nil {
+		m := Mapping{Gene: g, Species: s, Event: "speciation"}
+		s1, s2 := s.child1, s.child2
+		if g1.in[s1]+g2.in[s2] == opt {
+			choices = PairChoices(choices, m, InTargets(g1, s1, loss), InTargets(g2, s2, loss))
+		}
+		if g2.in[s1]+g1.in[s2] == opt {
+			choices = PairChoices(choices, m, InTargets(g1, s2, loss), InTargets(g2, s1, loss))
+		}
+	}
+	if g.duplication[s] == opt {
+		m := Mapping{Gene: g, Species: s, Event: "duplication"}
+		choices = PairChoices(choices, m, InTargets(g1, s, loss), InTargets(g2, s, loss))
+	}
+	if g.transfer[s] == opt {
+		best := Min2(g1.in[s]+g2.out[s], g2.in[s]+g1.out[s])
+		for _, pair := range [2][2]*Node{{g1, g2}, {g2, g1}} {
+			kept, moved := pair[0], pair[1]
+			if kept.in[s]+moved.out[s] != best {
+				continue
+			}
+			for _, r := range OutTargets(moved, s) {
+				m := Mapping{Gene: g, Species: s, Event: "transfer", Transferred: moved, Donor: s, Recipient: r}
+				for _, x := range InTargets(kept, s, loss) {
+					choices = append(choices, Choice{Mapping: m, Children: [2]Placement{x, {Gene: moved, Species: r}}})
+				}
+			}
+		}
+	}
+	return choices
+}
+
+// PairChoices appends a choice with the mapping m for every pair of placements of the two children.
+func PairChoices(choices []Choice, m Mapping, xs1, xs2 []Placement) []Choice {
+	for _, x1 := range xs1 {
+		for _, x2 := range xs2 {
+			choices = append(choices, Choice{Mapping: m, Children: [2]Placement{x1, x2}})
+		}
+	}
+	return choices
+}
+
+// InTargets takes in a gene node g and a species node e, and returns every species node x below e
+// with g.in[e] = g.cost[x] + PLoss * dist(e, x), with dist(e, x) as the number of losses.
+func InTargets(g, e *Node, loss float64) []Placement {
+	targets := make([]Placement, 0)
+	if math.IsInf(g.in[e], 1) {
+		return targets
+	}
+	AddInTargets(g, e, g.in[e], 0, loss, &targets)
+	return targets
+}
+
+// AddInTargets walks down from species node e, d losses below where the gene node entered, and appends
+// the species nodes whose cost plus losses still adds up to want.
+func AddInTargets(g, e *Node, want float64, d int, loss float64, targets *[]Placement) {
+	if g.in[e] != want {
+		return
+	}
+	if g.cost[e] == want {
+		*targets = append(*targets, Placement{Gene: g, Species: e, Losses: d})
+	}
+	if e.child1 != nil {
+		AddInTargets(g, e.child1, want-loss, d+1, loss, targets)
+		AddInTargets(g, e.child2, want-loss, d+1, loss, targets)
+	}
+}
+
+// OutTargets takes in a gene node g and a species node s, and returns every species node x
+// incomparable to s and time compatible with it with g.cost[x] = g.out[s].
+func OutTargets(g, s *Node) []*Node {
+	targets := make([]*Node, 0)
+	want := g.out[s]
+	if math.IsInf(want, 1) {
+		return targets
+	}
+	for p := s; p.parent != nil; p = p.parent {
+		sibling := p.parent.child1
+		if sibling == p {
+			sibling = p.parent.child2
+		}
+		AddAltTargets(g, s, sibling, want, &targets)
+	}
+	return targets
+}
+
+// AddAltTargets appends the species nodes x below e that can receive a transfer from s with g.cost[x] = want.
+func AddAltTargets(g, s, e *Node, want float64, targets *[]*Node) {
+	if g.inAlt[e] > want {
+		return
+	}
+	if g.cost[e] == want && TimeCompatible(s, e) {
+		*targets = append(*targets, e)
+	}
+	if e.child1 != nil {
+		AddAltTargets(g, s, e.child1, want, targets)
+		AddAltTargets(g, s, e.child2, want, targets)
+	}
+}
+
+// InitializeCounts clears the count and outside tables of every gene node.
+func InitializeCounts(geneT Tree, speciesN int) {
+	for _, g := range geneT {
+		g.count = make(map[*Node]*big.Int, speciesN)
+		g.outside = make(map[*Node]*big.Int, speciesN)
+	}
+}
+
+// Count takes in a gene node g placed on species node s and returns the number of optimal
+// reconciliations of the subtree of g, remembering the result in g.count.
+func Count(g, s *Node, loss float64) *big.Int {
+	if c, ok := g.count[s]; ok {
+		return c
+	}
+	c := big.NewInt(1)
+	if g.child1 != nil && g.child2 != nil {
+		c.SetInt64(0)
+		for _, ch := range Choices(g, s, loss) {
+			c.Add(c, ChoiceWeight(ch, loss))
+		}
+	}
+	g.count[s] = c
+	return c
+}
+
+// ChoiceWeight returns the number of optimal reconciliations below a choice.
+func ChoiceWeight(ch Choice, loss float64) *big.Int {
+	c1 := ch.Children[0]
+	c2 := ch.Children[1]
+	return new(big.Int).Mul(Count(c1.Gene, c1.Species, loss), Count(c2.Gene, c2.Species, loss))
+}
+
+// CountScenarios takes in the gene tree and the species tree after UMPR and the cost of a loss,
+// and returns the number of reconciliations with the optimal cost.
+func CountScenarios(geneT, speciesT Tree, PLoss int) *big.Int {
+	InitializeCounts(geneT, len(speciesT))
+	total := new(big.Int)
+	for _, root := range RootPlacements(geneT, speciesT) {
+		total.Add(total, Count(root.Gene, root.Species, float64(PLoss)))
+	}
+	return total
+}
+
+// EnumerateScenarios takes in the gene tree and the species tree after UMPR and the cost of a loss,
+// and calls visit with every reconciliation with the optimal cost until visit returns false.
+func EnumerateScenarios(geneT, speciesT Tree, PLoss int, visit func(Scenario) bool) {
+	g := geneT[len(geneT)-1]
+	cost := OptimalCost(g, speciesT)
+	mapping := make(map[*Node]*Mapping, len(geneT))
+	for _, root := range RootPlacements(geneT, speciesT) {
+		if !Enumerate(geneT, []Placement{root}, mapping, cost, float64(PLoss), visit) {
+			return
+		}
+	}
+}
+
+// Enumerate places the pending gene nodes in every optimal way, and calls visit once all gene nodes are placed.
+// It returns false as soon as visit does.
+func Enumerate(geneT Tree, pending []Placement, mapping map[*Node]*Mapping, cost, loss float64, visit func(Scenario) bool) bool {
+	if len(pending) == 0 {
+		return visit(BuildScenario(geneT, mapping, cost))
+	}
+	p := pending[len(pending)-1]
+	rest := pending[: len(pending)-1 : len(pending)-1]
+	if p.Gene.child1 == nil || p.Gene.child2 == nil {
+		mapping[p.Gene] = &Mapping{Gene: p.Gene, Species: p.Species, Event: "leaf", Losses: p.Losses}
+		return Enumerate(geneT, rest, mapping, cost, loss, visit)
+	}
+	for _, ch := range Choices(p.Gene, p.Species, loss) {
+		m := ch.Mapping
+		m.Losses = p.Losses
+		mapping[p.Gene] = &m
+		if !Enumerate(geneT, append(rest, ch.Children[0], ch.Children[1]), mapping, cost, loss, visit) {
+			return false
+		}
+	}
+	return true
+}
+
+// SampleScenario takes in the gene tree and the species tree after UMPR, the cost of a loss and a random
+// number generator, and returns a reconciliation drawn uniformly from all reconciliations with the optimal cost.
+// It returns an error if there is no reconciliation to draw from.
+func SampleScenario(geneT, speciesT Tree, PLoss int, rng *rand.Rand) (Scenario, error) {
+	loss := float64(PLoss)
+	total := CountScenarios(geneT, speciesT, PLoss)
+	if total.Sign() == 0 {
+		return Scenario{}, fmt.Errorf("sample: no reconciliation has a finite cost")
+	}
+	roots := RootPlacements(geneT, speciesT)
+	weights := make([]*big.Int, len(roots))
+	for i, root := range roots {
+		weights[i] = Count(root.Gene, root.Species, loss)
+	}
+	pending := []Placement{roots[PickWeighted(weights, total, rng)]}
+
+	mapping := make(map[*Node]*Mapping, len(geneT))
+	for len(pending) > 0 {
+		p := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if p.Gene.child1 == nil || p.Gene.child2 == nil {
+			mapping[p.Gene] = &Mapping{Gene: p.Gene, Species: p.Species, Event: "leaf", Losses: p.Losses}
+			continue
+		}
+		choices := Choices(p.Gene, p.Species, loss)
+		weights := make([]*big.Int, len(choices))
+		for i, ch := range choices {
+			weights[i] = ChoiceWeight(ch, loss)
+		}
+		ch := choices[PickWeighted(weights, Count(p.Gene, p.Species, loss), rng)]
+		m := ch.Mapping
+		m.Losses = p.Losses
+		mapping[p.Gene] = &m
+		pending = append(pending, ch.Children[0], ch.Children[1])
+	}
+	return BuildScenario(geneT, mapping, OptimalCost(geneT[len(geneT)-1], speciesT)), nil
+}
+
+// PickWeighted returns an index into weights, chosen with probability weights[i] / total.
+func PickWeighted(weights []*big.Int, total *big.Int, rng *rand.Rand) int {
+	r := new(big.Int).Rand(rng, total)
+	for i, w := range weights {
+		if r.Cmp(w) < 0 {
+			return i
+		}
+		r.Sub(r, w)
+	}
+	return len(weights) - 1
+}
+
+// EventSupports takes in the gene tree and the species tree after UMPR and the cost of a loss, and returns,
+// for every event on an internal gene node, how many of the optimal reconciliations contain it.
+// The events are listed in the order of the gene tree, most supported first.
+func EventSupports(geneT, speciesT Tree, PLoss int) []EventSupport {
+	loss := float64(PLoss)
+	total := CountScenarios(geneT, speciesT, PLoss)
+	for _, root := range RootPlacements(geneT, speciesT) {
+		root.Gene.outside[root.Species] = big.NewInt(1)
+	}
+
+	type key struct {
+		gene, species *Node
+		event         string
+		recipient     *Node
+	}
+	counts := make(map[key]*big.Int)
+	order := make([]key, 0)
+	// Walk down the gene tree so every node's outside count is complete before its children use it
+	for i := len(geneT) - 1; i >= 0; i-- {
+		g := geneT[i]
+		if g.child1 == nil || g.child2 == nil {
+			continue
+		}
+		for _, s := range speciesT {
+			out, ok := g.outside[s]
+			if !ok {
+				continue
+			}
+			for _, ch := range Choices(g, s, loss) {
+				c1 := ch.Children[0]
+				c2 := ch.Children[1]
+				k := key{g, s, ch.Mapping.Event, ch.Mapping.Recipient}
+				if counts[k] == nil {
+					counts[k] = new(big.Int)
+					order = append(order, k)
+				}
+				counts[k].Add(counts[k], new(big.Int).Mul(out, ChoiceWeight(ch, loss)))
+				AddOutside(c1, new(big.Int).Mul(out, Count(c2.Gene, c2.Species, loss)))
+				AddOutside(c2, new(big.Int).Mul(out, Count(c1.Gene, c1.Species, loss)))
+			}
+		}
+	}
+
+	position := make(map[*Node]int, len(geneT))
+	for i, g := range geneT {
+		position[g] = i
+	}
+	supports := make([]EventSupport, 0, len(order))
+	for _, k := range order {
+		freq, _ := new(big.Rat).SetFrac(counts[k], total).Float64()
+		supports = append(supports, EventSupport{Gene: k.gene, Species: k.species, Event: k.event, Recipient: k.recipient, Count: counts[k], Frequency: freq})
+	}
+	sort.SliceStable(supports, func(i, j int) bool {
+		if supports[i].Gene != supports[j].Gene {
+			return position[supports[i].Gene] < position[supports[j].Gene]
+		}
+		return supports[i].Count.Cmp(supports[j].Count) > 0
+	})
+	return supports
+}
+
+// AddOutside adds n to the outside count of a placement.
+func AddOutside(p Placement, n *big.Int) {
+	if p.Gene.outside[p.Species] == nil {
+		p.Gene.outside[p.Species] = new(big.Int)
+	}
+	p.Gene.outside[p.Species].Add(p.Gene.outside[p.Species], n)
+}
+
+// PrintEventSupports prints out how often each event appears among the optimal reconciliations.
+func PrintEventSupports(supports []EventSupport) {
+	for _, e := range supports {
+		fmt.Print("Gene: ", e.Gene.label, " Species: ", e.Species.label, " Event: ", e.Event)
+		if e.Event == "transfer" {
+			fmt.Print(" Recipient: ", e.Recipient.label)
+		}
+		fmt.Println(" Count: ", e.Count, " Frequency: ", e.Frequency)
+	}
+}
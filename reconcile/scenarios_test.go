@@ -0,0 +1,91 @@
+package reconcile
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestScenariosAreOptimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		geneT, speciesT := randomTrees(t, rng)
+		loss, dup, transfer := 1+rng.Intn(4), 1+rng.Intn(4), 1+rng.Intn(4)
+		cost, err := UMPR(geneT, speciesT, len(geneT), len(speciesT), loss, dup, transfer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := CountScenarios(geneT, speciesT, loss)
+		if !count.IsInt64() || count.Int64() > 10000 {
+			continue
+		}
+
+		enumerated := int64(0)
+		EnumerateScenarios(geneT, speciesT, loss, func(sc Scenario) bool {
+			enumerated++
+			if got := eventCost(sc, loss, dup, transfer); got != cost {
+				t.Errorf("case %d: enumerated scenario costs %v, optimum %v", i, got, cost)
+			}
+			return true
+		})
+		if enumerated != count.Int64() {
+			t.Errorf("case %d: enumerated %d scenarios, CountScenarios %v", i, enumerated, count)
+		}
+
+		for k := 0; k < 5; k++ {
+			sc, err := SampleScenario(geneT, speciesT, loss, rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := eventCost(sc, loss, dup, transfer); got != cost {
+				t.Errorf("case %d: sampled scenario costs %v, optimum %v", i, got, cost)
+			}
+		}
+	}
+}
+
+func TestEventSupportsCountEnumeratedScenarios(t *testing.T) {
+	type event struct {
+		gene, species *Node
+		event         string
+		recipient     *Node
+	}
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 200; i++ {
+		geneT, speciesT := randomTrees(t, rng)
+		loss, dup, transfer := 1+rng.Intn(4), 1+rng.Intn(4), 1+rng.Intn(4)
+		if _, err := UMPR(geneT, speciesT, len(geneT), len(speciesT), loss, dup, transfer); err != nil {
+			t.Fatal(err)
+		}
+		count := CountScenarios(geneT, speciesT, loss)
+		if !count.IsInt64() || count.Int64() > 10000 {
+			continue
+		}
+
+		// count the scenarios that contain each event of an internal gene node
+		want := make(map[event]int64)
+		EnumerateScenarios(geneT, speciesT, loss, func(sc Scenario) bool {
+			for _, m := range sc.Mappings {
+				if m.Event != "leaf" {
+					want[event{m.Gene, m.Species, m.Event, m.Recipient}]++
+				}
+			}
+			return true
+		})
+
+		supports := EventSupports(geneT, speciesT, loss)
+		if len(supports) != len(want) {
+			t.Errorf("case %d: %d supported events, %d in the enumerated scenarios", i, len(supports), len(want))
+		}
+		for _, e := range supports {
+			k := event{e.Gene, e.Species, e.Event, e.Recipient}
+			if !e.Count.IsInt64() || e.Count.Int64() != want[k] {
+				t.Errorf("case %d: %s on %s as %s has count %v, it is in %d of the enumerated scenarios",
+					i, e.Gene.label, e.Species.label, e.Event, e.Count, want[k])
+			}
+			if freq := float64(want[k]) / float64(count.Int64()); math.Abs(e.Frequency-freq) > 1e-12 {
+				t.Errorf("case %d: %s on %s as %s has frequency %v, want %v", i, e.Gene.label, e.Species.label, e.Event, e.Frequency, freq)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package reconcile
+
+import (
+	"fmt"
+	"math"
+)
+
+// Scenario is one optimal reconciliation of a gene tree with a species tree, rebuilt from the
+// cost tables that UMPR leaves on the gene tree nodes.
+type Scenario struct {
+	Cost                            float64
+	Duplications, Transfers, Losses int
+	Mappings                        []Mapping // one for every gene node, in the order of the gene tree
+}
+
+// Mapping records where one gene node is placed in the species tree and which event it represents.
+type Mapping struct {
+	Gene    *Node
+	Species *Node  // species node the gene node maps to
+	Event   string // "leaf", "speciation", "duplication" or "transfer"
+	// For a transfer, the gene child that leaves the species tree at Donor and lands on Recipient.
+	Transferred, Donor, Recipient *Node
+	Losses                        int // number of losses on the edge from the parent gene node to this one
+}
+
+// Traceback takes in the gene tree and species tree after UMPR has filled in the cost tables,
+// and the cost of a loss, and returns one optimal reconciliation. Ties are broken in the same
+// order as Min3: speciation, then duplication, then transfer.
+// It returns an error if the cost tables have no reconciliation with a finite cost.
+func Traceback(geneT, speciesT Tree, PLoss int) (Scenario, error) {
+	if len(geneT) == 0 || len(speciesT) == 0 || geneT[len(geneT)-1].cost == nil {
+		return Scenario{}, fmt.Errorf("traceback: UMPR has not been run on the trees")
+	}
+	if math.IsInf(OptimalCost(geneT[len(geneT)-1], speciesT), 1) {
+		return Scenario{}, fmt.Errorf("traceback: no reconciliation has a finite cost")
+	}
+	loss := float64(PLoss)
+	mapping := make(map[*Node]*Mapping, len(geneT))
+
+	// Place the gene root at the species node with the minimum cost
+	g := geneT[len(geneT)-1]
+	s := speciesT[0]
+	for _, node := range speciesT[1:] {
+		if g.cost[node] < g.cost[s] {
+			s = node
+		}
+	}
+	if err := TraceNode(g, s, 0, loss, mapping); err != nil {
+		return Scenario{}, err
+	}
+
+	sc := BuildScenario(geneT, mapping, g.cost[s])
+	for _, node := range geneT {
+		node.event = mapping[node].Event
+	}
+	return sc, nil
+}
+
+// BuildScenario takes in the gene tree, the mapping of every gene node and the total cost,
+// and collects them into a Scenario with the number of each kind of event.
+func BuildScenario(geneT Tree, mapping map[*Node]*Mapping, cost float64) Scenario {
+	sc := Scenario{Cost: cost}
+	for _, node := range geneT {
+		m := mapping[node]
+		sc.Mappings = append(sc.Mappings, *m)
+		sc.Losses += m.Losses
+		if m.Event == "duplication" {
+			sc.Duplications++
+		}
+		if m.Event == "transfer" {
+			sc.Transfers++
+		}
+	}
+	return sc
+}
+
+// TraceNode records that gene node g maps to species node s after losses losses on the edge above it,
+// works out which event gives g.cost[s], and places the children of g accordingly.
+// It returns an error if a transferred child has no species node to land on.
+func TraceNode(g, s *Node, losses int, loss float64, mapping map[*Node]*Mapping) error {
+	m := &Mapping{Gene: g, Species: s, Losses: losses}
+	mapping[g] = m
+	if g.child1 == nil || g.child2 == nil {
+		m.Event = "leaf"
+		return nil
+	}
+	g1 := g.child1
+	g2 := g.child2
+
+	switch g.cost[s] {
+	case g.speciation[s]:
+		// Each child enters one child of s and may go further down with losses
+		m.Event = "speciation"
+		s1, s2 := s.child1, s.child2
+		if g2.in[s1]+g1.in[s2] < g1.in[s1]+g2.in[s2] {
+			s1, s2 = s2, s1
+		}
+		x1, d1 := DescendIn(g1, s1, loss)
+		x2, d2 := DescendIn(g2, s2, loss)
+		if err := TraceNode(g1, x1, d1, loss, mapping); err != nil {
+			return err
+		}
+		return TraceNode(g2, x2, d2, loss, mapping)
+	case g.duplication[s]:
+		// Both children start at s and may go down with losses
+		m.Event = "duplication"
+		x1, d1 := DescendIn(g1, s, loss)
+		x2, d2 := DescendIn(g2, s, loss)
+		if err := TraceNode(g1, x1, d1, loss, mapping); err != nil {
+			return err
+		}
+		return TraceNode(g2, x2, d2, loss, mapping)
+	default:
+		// One child stays below s, the other is transferred to a species node incomparable to s
+		m.Event = "transfer"
+		kept, moved := g1, g2
+		if g2.in[s]+g1.out[s] < g1.in[s]+g2.out[s] {
+			kept, moved = g2, g1
+		}
+		x, d := DescendIn(kept, s, loss)
+		r := FindOut(moved, s)
+		if r == nil {
+			return fmt.Errorf("traceback: %s can't be transferred from species node %s", moved.label, s.label)
+		}
+		m.Transferred = moved
+		m.Donor = s
+		m.Recipient = r
+		if err := TraceNode(kept, x, d, loss, mapping); err != nil {
+			return err
+		}
+		return TraceNode(moved, r, 0, loss, mapping)
+	}
+}
+
+// DescendIn takes in a gene node g and a species node s and follows the in table down from s
+// to the species node x where g.in[s] = g.cost[x] + PLoss * dist(s, x). It returns x and dist(s, x).
+func DescendIn(g, s *Node, loss float64) (*Node, int) {
+	d := 0
+	for g.in[s] != g.cost[s] {
+		if g.in[s] == g.in[s.child1]+loss {
+			s = s.child1
+		} else {
+			s = s.child2
+		}
+		d++
+	}
+	return s, d
+}
+
+// FindOut takes in a gene node g and a species node s and returns the first species node x that can receive
+// a transfer from s with g.cost[x] = g.out[s], searching the nearest incomparable subtrees first.
+// It returns nil if no species node can receive the transfer.
+func FindOut(g, s *Node) *Node {
+	targets := OutTargets(g, s)
+	if len(targets) == 0 {
+		return nil
+	}
+	return targets[0]
+}
+
+// PrintScenario prints out the species node, event and losses for each gene node of a reconciliation.
+func PrintScenario(sc Scenario) {
+	fmt.Println("Cost: ", sc.Cost, " Duplications: ", sc.Duplications, " Transfers: ", sc.Transfers, " Losses: ", sc.Losses)
+	for _, m := range sc.Mappings {
+		fmt.Print("Gene: ", NodeLabel(m.Gene), " Species: ", NodeLabel(m.Species), " Event: ", m.Event, " Losses: ", m.Losses)
+		if m.Event == "transfer" {
+			fmt.Print(" Transferred: ", NodeLabel(m.Transferred), " Donor: ", NodeLabel(m.Donor), " Recipient: ", NodeLabel(m.Recipient))
+		}
+		fmt.Println()
+	}
+}
+
+// NodeLabel returns the label of a node, or "-" for a node that is missing from a mapping.
+func NodeLabel(n *Node) string {
+	if n == nil {
+		return "-"
+	}
+	return n.label
+}
@@ -1,10 +1,15 @@
-package main
+package reconcile
 
 import (
 	"fmt"
 	"math"
+	"math/big"
 )
 
+//Reconciliation of a gene tree with a species tree using U-MPR
+//Input: rooted binary gene tree G, rooted binary species tree S, and the costs of a loss, a duplication and a transfer
+//Output: the minimum cost of a reconciliation of G with S by speciations, duplications, transfers and losses
+
 type Matrix [][]float64
 
 type Tree []*Node
@@ -17,60 +22,36 @@ type Node struct {
 	parent, child1, child2                                  *Node
 	L                                                       []*Node // Possible to have one gene map to multiple species?
 	cost, speciation, duplication, transfer, in, inAlt, out map[*Node]float64
+	count, outside                                          map[*Node]*big.Int // number of optimal reconciliations below and around a mapping
+	number                                                  int                // preorder number of a species node, or of the species node a gene node maps to (LabelEvents)
+	dist                                                    float64            // length of the branch to the parent, if the input tree has one
+	hasDist                                                 bool
+}
+
+// Label returns the label of a node: a species or gene name, or the name given to an internal node by FromTree.
+func (n *Node) Label() string {
+	return n.label
 }
 
-func main() {
-	fmt.Println("Reconciliation using U-MPR")
-
-	var speciesTree Tree
-	speciesTree = make([]*Node, 7)
-	var v0, v1, v2, v3, v4, v5, v6 Node
-	v0 = Node{parent: &v4, label: "A"}
-	v1 = Node{parent: &v4, label: "B"}
-	v2 = Node{parent: &v5, label: "C"}
-	v3 = Node{parent: &v6, label: "D"}
-	v4 = Node{parent: &v5, child1: &v0, child2: &v1, label: "Ancestor Species 1"}
-	v5 = Node{parent: &v6, child1: &v4, child2: &v2, label: "Ancestor Species 2"}
-	v6 = Node{child1: &v5, child2: &v3, label: "Ancestor Species 3"}
-	speciesTree[0] = &v0
-	speciesTree[1] = &v1
-	speciesTree[2] = &v2
-	speciesTree[3] = &v3
-	speciesTree[4] = &v4
-	speciesTree[5] = &v5
-	speciesTree[6] = &v6
-
-	var geneTree Tree
-	geneTree = make([]*Node, 7)
-	var gv0, gv1, gv2, gv3, gv4, gv5, gv6 Node
-	gv0 = Node{parent: &gv4, label: "A"}
-	gv1 = Node{parent: &gv4, label: "C"}
-	gv2 = Node{parent: &gv5, label: "B"}
-	gv3 = Node{parent: &gv6, label: "D"}
-	gv4 = Node{parent: &gv5, child1: &gv0, child2: &gv1, label: "Ancestor Species 1"}
-	gv5 = Node{parent: &gv6, child1: &gv4, child2: &gv2, label: "Ancestor Species 2"}
-	gv6 = Node{child1: &gv5, child2: &gv3, label: "Ancestor Species 3"}
-	geneTree[0] = &gv0
-	geneTree[1] = &gv1
-	geneTree[2] = &gv2
-	geneTree[3] = &gv3
-	geneTree[4] = &gv4
-	geneTree[5] = &gv5
-	geneTree[6] = &gv6
-
-	//var Lmap map[]
-	PLoss := 3
-	Pduplication := 2
-	Ptransfer := 1
-
-	cost := UMPR(geneTree, speciesTree, len(geneTree), len(speciesTree), PLoss, Pduplication, Ptransfer)
-	fmt.Println("Optimal cost is: ", cost)
+// Event returns the event of an internal gene tree node, which is set by LabelEvents and Traceback. UMPR only
+// computes the costs and clears the events, since the event of a node depends on the species node it maps to.
+func (n *Node) Event() string {
+	return n.event
 }
 
 // UMPR takes in both gene tree and species tree, the length of gene tree and species tree, and
-// the cost of loss, duplication, and Ptransfer, and returns the minimum cost after inferring
-// evolutionary events for every internal nodes in the gene tree.
-func UMPR(geneT, speciesT Tree, geneN, speciesN, PLoss, Pduplication, Ptransfer int) float64 {
+// the cost of loss, duplication, and Ptransfer, and returns the minimum cost of a reconciliation.
+// It fills in the cost tables of the gene tree nodes and clears their events; Traceback rebuilds an optimal
+// reconciliation from the tables and sets the event of every gene node.
+// It returns an error if the trees are not laid out as FromTree lays them out, geneN and speciesN are not their
+// lengths, or a gene tree leaf is not a species tree leaf.
+func UMPR(geneT, speciesT Tree, geneN, speciesN, PLoss, Pduplication, Ptransfer int) (float64, error) {
+	if err := CheckReconciliation(geneT, speciesT); err != nil {
+		return 0, err
+	}
+	if geneN != len(geneT) || speciesN != len(speciesT) {
+		return 0, fmt.Errorf("the trees have %d and %d nodes, not %d and %d", len(geneT), len(speciesT), geneN, speciesN)
+	}
 	//Initiailize tree
 	InitializeTree(geneT, speciesN)
 
@@ -109,12 +90,9 @@ func UMPR(geneT, speciesT Tree, geneN, speciesN, PLoss, Pduplication, Ptransfer
 
 			l.inAlt[a] = 0.0
 		}
-		specieNodes := GetLeaveNodes(speciesT, speciesLeave)
-		for _, i := range specieNodes {
-			if i.label != l.label {
-				l.out[i] = 0
-			}
-		}
+		// out(g,s) is 0 on every species node that is neither L(g) nor one of its ancestors
+		// and, in a dated species tree, whose branch overlaps in time with the branch above L(g)
+		ComputeOut(speciesT, l)
 	}
 
 	// Get internal nodes of gene tree
@@ -129,15 +107,34 @@ func UMPR(geneT, speciesT Tree, geneN, speciesN, PLoss, Pduplication, Ptransfer
 	rootNode := geneT[len(geneT)-1]
 	cost := OptimalCost(rootNode, speciesT)
 
-	PrintTree(geneT)
+	return cost, nil
+}
 
-	return cost
+// CheckReconciliation returns an error if a gene tree can't be reconciled with a species tree: both must be laid
+// out as FromTree lays them out, the species tree nodes must have distinct labels, and every gene tree leaf must
+// be labelled with the name of a species tree leaf.
+func CheckReconciliation(geneT, speciesT Tree) error {
+	if err := CheckTree(geneT); err != nil {
+		return fmt.Errorf("gene tree: %w", err)
+	}
+	if err := CheckTree(speciesT); err != nil {
+		return fmt.Errorf("species tree: %w", err)
+	}
+	seen := make(map[string]bool, len(speciesT))
+	for _, s := range speciesT {
+		if seen[s.label] {
+			return fmt.Errorf("species tree: label %q appears more than once", s.label)
+		}
+		seen[s.label] = true
+	}
+	return CheckLeaves(geneT, speciesT, LeafNum(speciesT))
 }
 
 // InitializeTree takes in a gene tree and number of species tree to initialize
-// the cost maps that we will use in our dynamic programming to get the minimum cost.
+// the cost maps that we will use in our dynamic programming to get the minimum cost, and clears the events.
 func InitializeTree(geneT Tree, speciesN int) {
 	for _, i := range geneT {
+		i.event = ""
 		i.cost = make(map[*Node]float64, speciesN)
 		i.speciation = make(map[*Node]float64, speciesN)
 		i.duplication = make(map[*Node]float64, speciesN)
@@ -173,18 +170,13 @@ func PostOrderGeneT(internalNodes, speciesT, geneT Tree, g, s *Node, speciesLeav
 
 	PostOrderSpT(speciesT, geneT, g, s, speciesLeave, Pduplication, Ptransfer, PLoss)
 
-	rootNode := speciesT[len(speciesT)-1]
-	PreOrderIntNSpT(speciesT, rootNode, g)
+	ComputeOut(speciesT, g)
 }
 
 // PreOrderIntNSpT takes in the species tree, the root node of the species tree,
 // and one internal
 func PreOrderIntNSpT(speciesT Tree, rootNode, g *Node) {
-	if rootNode == nil {
-		panic("Species tree do not have internal nodes!")
-	}
-
-	if rootNode.child1 == nil || rootNode.child2 == nil {
+	if rootNode == nil || rootNode.child1 == nil || rootNode.child2 == nil {
 		return
 	}
 
@@ -220,8 +212,7 @@ func PostOrderSpT(speciesT, geneT Tree, g, s *Node, speciesLeave, Pduplication,
 			g.transfer[s] = float64(Ptransfer) + Min2(g1.in[s]+g2.out[s], g2.in[s]+g1.out[s])
 		}
 		//	fmt.Println("THE NUMBER: ", g.speciation[s], " ", g.duplication[s], " ", g.transfer[s])
-		g.cost[s], g.event = Min3(g.speciation[s], g.duplication[s], g.transfer[s])
-		//	fmt.Println("HY lavbel: ", g.label, "MAP TO: ", s.label, " event: ", g.event)
+		g.cost[s], _ = Min3(g.speciation[s], g.duplication[s], g.transfer[s])
 
 		g.in[s] = g.cost[s]
 		g.inAlt[s] = g.cost[s]
@@ -236,7 +227,7 @@ func PostOrderSpT(speciesT, geneT Tree, g, s *Node, speciesLeave, Pduplication,
 
 			g.transfer[s] = float64(Ptransfer) + Min2(g1.in[s]+g2.out[s], g2.in[s]+g1.out[s])
 		}
-		g.cost[s], g.event = Min3(g.speciation[s], g.duplication[s], g.transfer[s])
+		g.cost[s], _ = Min3(g.speciation[s], g.duplication[s], g.transfer[s])
 
 		g.in[s] = Min3M(g.cost[s], g.in[s1]+float64(PLoss), g.in[s2]+float64(PLoss))
 		g.inAlt[s] = Min3M(g.cost[s], g.inAlt[s1], g.inAlt[s2])
@@ -269,19 +260,15 @@ func Min9(a, b, c, d, e, f, g, h, i float64) float64 {
 	return Min(arr)
 }
 
-// Min takes in an array and returns the minimum value in this array
+// Min takes in an array and returns the minimum value in this array, or +Inf if it is empty
 func Min(arr []float64) float64 {
-	if len(arr) < 1 {
-		panic("no items in array")
-	}
-	if len(arr) == 1 {
-		return arr[0]
-	}
-	if arr[0] < arr[1] {
-		return arr[0]
+	min := math.Inf(1)
+	for _, v := range arr {
+		if v < min {
+			min = v
+		}
 	}
-	arr = arr[1:]
-	return Min(arr)
+	return min
 }
 
 // Min3 takes in three variables and returns the minimum value, and also the event
@@ -416,10 +403,3 @@ func GetLeaveNodes(geneT Tree, leaveNum int) Tree {
 	}
 	return leaves
 }
-
-// PrintTree takes in a tree and prints out the mapping for each nodes
-func PrintTree(t Tree) {
-	for i := range t {
-		fmt.Println("Label: ", t[i].label, " Events: ", t[i].event)
-	}
-}
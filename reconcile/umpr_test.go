@@ -0,0 +1,202 @@
+package reconcile
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomNewick joins the leaves in a random order into a rooted binary Newick tree.
+func randomNewick(rng *rand.Rand, leaves []string) string {
+	subtrees := append([]string(nil), leaves...)
+	for len(subtrees) > 1 {
+		i := rng.Intn(len(subtrees))
+		a := subtrees[i]
+		subtrees = append(subtrees[:i], subtrees[i+1:]...)
+		j := rng.Intn(len(subtrees))
+		subtrees[j] = "(" + a + "," + subtrees[j] + ")"
+	}
+	return subtrees[0] + ";"
+}
+
+// randomTrees returns a random species tree with 2 to 6 leaves and a random gene tree with 2 to 8 leaves
+// labelled with its species.
+func randomTrees(t *testing.T, rng *rand.Rand) (Tree, Tree) {
+	species := make([]string, 2+rng.Intn(5))
+	for i := range species {
+		species[i] = fmt.Sprintf("S%d", i)
+	}
+	genes := make([]string, 2+rng.Intn(7))
+	for i := range genes {
+		genes[i] = species[rng.Intn(len(species))]
+	}
+	speciesT, err := ParseNewick(randomNewick(rng, species))
+	if err != nil {
+		t.Fatal(err)
+	}
+	geneT, err := ParseNewick(randomNewick(rng, genes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return geneT, speciesT
+}
+
+// eventCost returns the cost of a scenario worked out from its events.
+func eventCost(sc Scenario, loss, dup, transfer int) float64 {
+	return float64(sc.Losses*loss + sc.Duplications*dup + sc.Transfers*transfer)
+}
+
+func TestMin(t *testing.T) {
+	tests := []struct {
+		arr  []float64
+		want float64
+	}{
+		{[]float64{3, 5, 1}, 1},
+		{[]float64{1, 5, 3}, 1},
+		{[]float64{5, 3, 3}, 3},
+		{[]float64{2}, 2},
+	}
+	for _, test := range tests {
+		if got := Min(test.arr); got != test.want {
+			t.Errorf("Min(%v) = %v, want %v", test.arr, got, test.want)
+		}
+	}
+	if got := Min(nil); !math.IsInf(got, 1) {
+		t.Errorf("Min(nil) = %v, want +Inf", got)
+	}
+}
+
+func TestTracebackCostMatchesEvents(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 400; i++ {
+		geneT, speciesT := randomTrees(t, rng)
+		loss, dup, transfer := 1+rng.Intn(4), 1+rng.Intn(4), 1+rng.Intn(4)
+		cost, err := UMPR(geneT, speciesT, len(geneT), len(speciesT), loss, dup, transfer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sc, err := Traceback(geneT, speciesT, loss)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sc.Cost != cost {
+			t.Fatalf("case %d: scenario cost %v, UMPR cost %v", i, sc.Cost, cost)
+		}
+		if got := eventCost(sc, loss, dup, transfer); got != sc.Cost {
+			t.Fatalf("case %d: cost %v, but %d losses, %d duplications and %d transfers cost %v",
+				i, sc.Cost, sc.Losses, sc.Duplications, sc.Transfers, got)
+		}
+	}
+}
+
+// nodeByLabel returns the node of a tree with the given label.
+func nodeByLabel(t *testing.T, tr Tree, label string) *Node {
+	for _, n := range tr {
+		if n.label == label {
+			return n
+		}
+	}
+	t.Fatalf("no node %q", label)
+	return nil
+}
+
+func TestLeafOut(t *testing.T) {
+	// out(g,s) of a gene leaf is 0 on the species nodes incomparable to its species, internal ones included
+	geneT, err := ParseNewick("(A,(C,D)CD)g;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	speciesT, err := ParseNewick("((A,B)AB,(C,D)CD)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost, err := UMPR(geneT, speciesT, len(geneT), len(speciesT), 3, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := nodeByLabel(t, geneT, "A")
+	want := map[string]float64{"A": math.Inf(1), "B": 0, "AB": math.Inf(1), "C": 0, "D": 0, "CD": 0, "R": math.Inf(1)}
+	for label, w := range want {
+		if got := leaf.out[nodeByLabel(t, speciesT, label)]; got != w {
+			t.Errorf("out(A, %s) = %v, want %v", label, got, w)
+		}
+	}
+
+	// A transfer between the two sides of the root costs 1, less than the loss of B in a speciation at the root
+	if cost != 1 {
+		t.Fatalf("UMPR cost %v, want 1", cost)
+	}
+
+	// At a speciation at the root, g must go to the side of B and C: it maps to BC with a transfer of A from BC.
+	// Without out(A, BC), g can only map to A, and the root becomes a duplication on A.
+	geneT, err = ParseNewick("((A,(B,C)BC)g,A)p;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	speciesT, err = ParseNewick("((C,B)BC,A)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost, err = UMPR(geneT, speciesT, len(geneT), len(speciesT), 2, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 2 {
+		t.Fatalf("UMPR cost %v, want 2", cost)
+	}
+	sc, err := Traceback(geneT, speciesT, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := nodeByLabel(t, geneT, "g")
+	for _, m := range sc.Mappings {
+		if m.Gene == g && (m.Event != "transfer" || NodeLabel(m.Donor) != "BC" || NodeLabel(m.Recipient) != "A") {
+			t.Errorf("gene node g: %s from %s to %s, want a transfer from BC to A", m.Event, NodeLabel(m.Donor), NodeLabel(m.Recipient))
+		}
+	}
+}
+
+func TestTracebackWithoutRecipient(t *testing.T) {
+	geneT, err := ParseNewick("(A,(C,D)CD)g;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	speciesT, err := ParseNewick("((A,B)AB,(C,D)CD)R;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UMPR(geneT, speciesT, len(geneT), len(speciesT), 3, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	// Make the transferred child cost less outside the donor than it costs on any species node
+	nodeByLabel(t, geneT, "A").out[nodeByLabel(t, speciesT, "CD")] = 0.5
+	nodeByLabel(t, geneT, "CD").out[nodeByLabel(t, speciesT, "A")] = 0.5
+	if _, err := Traceback(geneT, speciesT, 3); err == nil {
+		t.Error("Traceback found a recipient for a transfer that has none")
+	}
+}
+
+func TestEventsSetByTraceback(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 100; i++ {
+		geneT, speciesT := randomTrees(t, rng)
+		if _, err := UMPR(geneT, speciesT, len(geneT), len(speciesT), 1, 2, 3); err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range geneT {
+			if n.Event() != "" {
+				t.Fatalf("case %d: gene node %s has event %q after UMPR alone", i, n.label, n.Event())
+			}
+		}
+		sc, err := Traceback(geneT, speciesT, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range sc.Mappings {
+			if m.Gene.Event() != m.Event {
+				t.Fatalf("case %d: gene node %s has event %q, the traceback maps it with %q", i, m.Gene.label, m.Gene.Event(), m.Event)
+			}
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package splits
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Tree distances
+// All distances compare two trees on the same taxa, using the splits of their branches.
+//   rf       Robinson-Foulds: the number of non-trivial splits found in only one of the trees
+//   nrf      Robinson-Foulds divided by its largest value for the number of taxa, 2(n-3) unrooted or 2(n-2) rooted
+//   wrf      weighted Robinson-Foulds: the sum over all splits, leaf branches included, of the difference in
+//            branch length, a split missing from a tree having length 0
+//   quartet  the number of sets of four taxa whose unrooted subtrees differ; a resolved and an unresolved
+//            subtree differ
+//   ms       matching split distance: the cost of the cheapest one-to-one matching of the splits of the two
+//            trees, a pair costing the number of taxa to move to turn one split into the other
+// The quartet distance looks only at unrooted trees.
+
+// Tree distance metrics
+const (
+	RF            = "rf"
+	NormalizedRF  = "nrf"
+	WeightedRF    = "wrf"
+	Quartet       = "quartet"
+	MatchingSplit = "ms"
+)
+
+// TreeDistance returns the distance between two trees on the same taxa with the given metric.
+func TreeDistance(t1, t2 *tree.Tree, metric string, rooted bool) (float64, error) {
+	taxa := Taxa(t1)
+	if err := CheckTaxa([]*tree.Tree{t2}, taxa); err != nil {
+		return 0, err
+	}
+	switch metric {
+	case RF:
+		return float64(RobinsonFoulds(t1, t2, taxa, rooted)), nil
+	case NormalizedRF:
+		return NormalizedRobinsonFoulds(t1, t2, taxa, rooted), nil
+	case WeightedRF:
+		return WeightedRobinsonFoulds(t1, t2, taxa, rooted), nil
+	case Quartet:
+		return float64(QuartetDistance(t1, t2, taxa)), nil
+	case MatchingSplit:
+		return float64(MatchingSplitDistance(t1, t2, taxa, rooted)), nil
+	}
+	return 0, fmt.Errorf("unknown tree distance %q", metric)
+}
+
+// RobinsonFoulds returns the number of non-trivial splits that are in one tree but not in the other.
+func RobinsonFoulds(t1, t2 *tree.Tree, taxa []string, rooted bool) int {
+	index := TaxaIndex(taxa)
+	splits1 := TreeSplits(t1, index, rooted)
+	splits2 := TreeSplits(t2, index, rooted)
+	distance := 0
+	for key := range splits1 {
+		if _, ok := splits2[key]; !ok {
+			distance++
+		}
+	}
+	for key := range splits2 {
+		if _, ok := splits1[key]; !ok {
+			distance++
+		}
+	}
+	return distance
+}
+
+// NormalizedRobinsonFoulds returns the Robinson-Foulds distance divided by the distance between two binary trees
+// that share no split, so it is between 0 and 1.
+func NormalizedRobinsonFoulds(t1, t2 *tree.Tree, taxa []string, rooted bool) float64 {
+	max := 2 * (len(taxa) - 3)
+	if rooted {
+		max = 2 * (len(taxa) - 2)
+	}
+	if max <= 0 {
+		return 0
+	}
+	return float64(RobinsonFoulds(t1, t2, taxa, rooted)) / float64(max)
+}
+
+// WeightedRobinsonFoulds returns the sum of the differences in branch length of all splits of the two trees.
+func WeightedRobinsonFoulds(t1, t2 *tree.Tree, taxa []string, rooted bool) float64 {
+	index := TaxaIndex(taxa)
+	lengths1 := BranchLengths(t1, index, rooted)
+	lengths2 := BranchLengths(t2, index, rooted)
+	distance := 0.0
+	for key, length := range lengths1 {
+		distance += math.Abs(length - lengths2[key])
+	}
+	for key, length := range lengths2 {
+		if _, ok := lengths1[key]; !ok {
+			distance += math.Abs(length)
+		}
+	}
+	return distance
+}
+
+// QuartetDistance returns the number of quartets of taxa that have different topologies in the two trees.
+// It looks at all n^4/24 quartets, which is fine for the tens of taxa of a gene family.
+func QuartetDistance(t1, t2 *tree.Tree, taxa []string) int {
+	index := TaxaIndex(taxa)
+	d1 := PathLengths(t1, index)
+	d2 := PathLengths(t2, index)
+	n := len(taxa)
+	distance := 0
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			for c := b + 1; c < n; c++ {
+				for d := c + 1; d < n; d++ {
+					if QuartetTopology(d1, a, b, c, d) != QuartetTopology(d2, a, b, c, d) {
+						distance++
+					}
+				}
+			}
+		}
+	}
+	return distance
+}
+
+// QuartetTopology takes the number of branches between each pair of taxa and returns which pairing of a, b, c
+// and d the tree has: 1 for ab|cd, 2 for ac|bd, 3 for ad|bc, and 0 if the quartet is unresolved.
+// The two taxa pairs of the tree's pairing are the ones whose paths do not share a branch, so their total length
+// is the smallest.
+func QuartetTopology(dist [][]int, a, b, c, d int) int {
+	ab := dist[a][b] + dist[c][d]
+	ac := dist[a][c] + dist[b][d]
+	ad := dist[a][d] + dist[b][c]
+	switch {
+	case ab < ac && ab < ad:
+		return 1
+	case ac < ab && ac < ad:
+		return 2
+	case ad < ab && ad < ac:
+		return 3
+	}
+	return 0
+}
+
+// PathLengths returns the number of branches on the path between each pair of taxa of a tree.
+func PathLengths(t *tree.Tree, index map[string]int) [][]int {
+	dist := make([][]int, len(index))
+	for i := range dist {
+		dist[i] = make([]int, len(index))
+	}
+	for _, leaf := range t.Leaves() {
+		//walk up from the leaf, and from each node on the way down into the subtrees off the path
+		from := index[leaf.Label]
+		steps := 0
+		for prev, n := leaf, leaf.Parent; n != nil; prev, n = n, n.Parent {
+			steps++
+			for _, child := range n.Children {
+				if child != prev {
+					WalkDown(child, steps+1, index, dist[from])
+				}
+			}
+		}
+	}
+	return dist
+}
+
+// WalkDown sets the number of branches from a leaf to every taxon below n, steps being the number to n.
+func WalkDown(n *tree.Node, steps int, index map[string]int, dist []int) {
+	if len(n.Children) == 0 {
+		dist[index[n.Label]] = steps
+		return
+	}
+	for _, child := range n.Children {
+		WalkDown(child, steps+1, index, dist)
+	}
+}
+
+// MatchingSplitDistance returns the cost of the cheapest matching of the non-trivial splits of the two trees.
+// The tree with fewer splits gets empty splits, which cost the size of the smaller side of their partner.
+func MatchingSplitDistance(t1, t2 *tree.Tree, taxa []string, rooted bool) int {
+	index := TaxaIndex(taxa)
+	splits1 := SplitList(TreeSplits(t1, index, rooted))
+	splits2 := SplitList(TreeSplits(t2, index, rooted))
+	empty := strings.Repeat("0", len(taxa))
+	for len(splits1) < len(splits2) {
+		splits1 = append(splits1, empty)
+	}
+	for len(splits2) < len(splits1) {
+		splits2 = append(splits2, empty)
+	}
+	cost := make([][]int, len(splits1))
+	for i := range splits1 {
+		cost[i] = make([]int, len(splits2))
+		for j := range splits2 {
+			cost[i][j] = SplitDistance(splits1[i], splits2[j], rooted)
+		}
+	}
+	return MinCostMatching(cost)
+}
+
+// SplitList returns the keys of a set of splits.
+func SplitList(splits map[string]*tree.Node) []string {
+	keys := make([]string, 0, len(splits))
+	for key := range splits {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// SplitDistance returns the number of taxa that have to change sides to turn split a into split b.
+// The sides of unrooted splits have no order, so they may also be swapped.
+func SplitDistance(a, b string, rooted bool) int {
+	differ := 0
+	for k := 0; k < len(a); k++ {
+		if a[k] != b[k] {
+			differ++
+		}
+	}
+	if !rooted && len(a)-differ < differ {
+		return len(a) - differ
+	}
+	return differ
+}
+
+// MinCostMatching takes a square cost matrix and returns the cost of the cheapest assignment of rows to columns,
+// with the Hungarian method in O(n^3).
+func MinCostMatching(cost [][]int) int {
+	n := len(cost)
+	//u and v are the row and column potentials, match[j] the row assigned to column j; row and column 0 are
+	//a sentinel and the matrix is read 1-based
+	u := make([]int, n+1)
+	v := make([]int, n+1)
+	match := make([]int, n+1)
+	way := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		match[0] = i
+		j0 := 0
+		minv := make([]int, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = math.MaxInt32
+		}
+		for match[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := match[j0], math.MaxInt32, 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[match[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+		//flip the augmenting path
+		for j0 != 0 {
+			j1 := way[j0]
+			match[j0] = match[j1]
+			j0 = j1
+		}
+	}
+	total := 0
+	for j := 1; j <= n; j++ {
+		total += cost[match[j]-1][j-1]
+	}
+	return total
+}
+
+// DistanceMatrix returns the distance between each pair of trees.
+func DistanceMatrix(trees []*tree.Tree, metric string, rooted bool) ([][]float64, error) {
+	matrix := make([][]float64, len(trees))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(trees))
+	}
+	for i := 0; i < len(trees); i++ {
+		for j := i + 1; j < len(trees); j++ {
+			d, err := TreeDistance(trees[i], trees[j], metric, rooted)
+			if err != nil {
+				return nil, fmt.Errorf("trees %d and %d: %w", i+1, j+1, err)
+			}
+			matrix[i][j] = d
+			matrix[j][i] = d
+		}
+	}
+	return matrix, nil
+}
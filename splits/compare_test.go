@@ -0,0 +1,86 @@
+package splits
+
+import (
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// parse returns the tree of a Newick string.
+func parse(t *testing.T, s string) *tree.Tree {
+	nt, err := tree.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nt
+}
+
+func TestTreeDistance(t *testing.T) {
+	tests := []struct {
+		t1, t2, metric string
+		rooted         bool
+		want           float64
+	}{
+		// the trees differ by the split of A and B against that of A and C; of the five quartets,
+		// ABCD and ABCE differ; matching AB|CDE with AC|BDE moves B and C
+		{"((A,B),C,(D,E));", "((A,C),B,(D,E));", RF, false, 2},
+		{"((A,B),C,(D,E));", "((A,C),B,(D,E));", Quartet, false, 2},
+		{"((A,B),C,(D,E));", "((A,C),B,(D,E));", MatchingSplit, false, 2},
+		{"((A,B),C,(D,E));", "((A,C),B,(D,E));", NormalizedRF, false, 0.5},
+		{"((A,B),C,(D,E));", "((A,B),C,(D,E));", RF, false, 0},
+		{"((A,B),C,(D,E));", "((A,B),C,(D,E));", Quartet, false, 0},
+		{"((A,B),C,(D,E));", "((A,B),C,(D,E));", MatchingSplit, false, 0},
+		{"((A,B),C,(D,E));", "(E,D,(C,(B,A)));", RF, false, 0},
+		// the same unrooted tree rooted in two places: AB|CD against the clades AB, CD and BCD, CD
+		{"((A,B),(C,D));", "(A,(B,(C,D)));", RF, false, 0},
+		{"((A,B),(C,D));", "(A,(B,(C,D)));", RF, true, 2},
+		{"((A,B),(C,D));", "((A,C),(B,D));", RF, false, 2},
+		{"((A,B),(C,D));", "((A,C),(B,D));", RF, true, 4},
+		// the star tree has no splits: the binary tree's two splits are matched with empty splits, each costing
+		// the two taxa of its smaller side, and every quartet is resolved in only one tree
+		{"((A,B),C,(D,E));", "(A,B,C,D,E);", RF, false, 2},
+		{"((A,B),C,(D,E));", "(A,B,C,D,E);", MatchingSplit, false, 4},
+		{"(A,B,C,D,E);", "((A,B),C,(D,E));", MatchingSplit, false, 4},
+		{"((A,B),C,(D,E));", "(A,B,C,D,E);", Quartet, false, 5},
+		// AB|CDE has length 2 and 1; the leaf branches and DE|ABC are the same
+		{"((A:1,B:1):2,C:1,(D:1,E:1):3);", "((A:1,B:1):1,C:1,(D:1,E:1):3);", WeightedRF, false, 1},
+		// the two root branches of an unrooted tree are one split of length 2+3
+		{"((A:1,B:1):2,(C:1,D:1):3);", "((A:1,B:1):5,C:1,D:1);", WeightedRF, false, 0},
+		{"((A:1,B:1):2,C:1,(D:1,E:1):3);", "((A:1,C:1):2,B:1,(D:1,E:1):3);", WeightedRF, false, 4},
+	}
+	for _, test := range tests {
+		got, err := TreeDistance(parse(t, test.t1), parse(t, test.t2), test.metric, test.rooted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%s distance between %s and %s (rooted %v) = %v, want %v", test.metric, test.t1, test.t2, test.rooted, got, test.want)
+		}
+	}
+
+	if _, err := TreeDistance(parse(t, "((A,B),C,(D,E));"), parse(t, "((A,B),C,(D,F));"), RF, false); err == nil {
+		t.Error("TreeDistance compared trees on different taxa")
+	}
+	if _, err := TreeDistance(parse(t, "((A,B),C,D);"), parse(t, "((A,B),C,D);"), "spr", false); err == nil {
+		t.Error("TreeDistance accepted an unknown metric")
+	}
+}
+
+func TestMinCostMatching(t *testing.T) {
+	tests := []struct {
+		cost [][]int
+		want int
+	}{
+		{[][]int{}, 0},
+		{[][]int{{7}}, 7},
+		{[][]int{{4, 1, 3}, {2, 0, 5}, {3, 2, 2}}, 5},
+		// the cheapest entry of each row is in the same column
+		{[][]int{{1, 5, 9}, {1, 6, 9}, {1, 9, 9}}, 15},
+		{[][]int{{9, 2, 7, 8}, {6, 4, 3, 7}, {5, 8, 1, 8}, {7, 6, 9, 4}}, 13},
+	}
+	for _, test := range tests {
+		if got := MinCostMatching(test.cost); got != test.want {
+			t.Errorf("MinCostMatching(%v) = %d, want %d", test.cost, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package splits
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Consensus trees
+// A consensus tree summarizes trees on the same taxa (bootstrap replicates, equally parsimonious trees, gene trees
+// of several loci) by the splits they share. Each internal node of the consensus is labelled with the percentage
+// of trees that have the split of its branch.
+//   strict    the splits found in every tree
+//   majority  the splits found in more than a threshold fraction of the trees (at least one half)
+//   greedy    extended majority rule: the majority splits, then the other splits from the most frequent down,
+//             each one kept if it is compatible with all the splits kept before it; splits as frequent as each
+//             other are taken in decreasing order of their key over the taxa of the first tree
+
+// Consensus methods
+const (
+	Strict   = "strict"
+	Majority = "majority"
+	Greedy   = "greedy"
+)
+
+// SplitFrequencies returns the fraction of trees that have each non-trivial split.
+func SplitFrequencies(trees []*tree.Tree, taxa []string, rooted bool) map[string]float64 {
+	index := TaxaIndex(taxa)
+	counts := make(map[string]int)
+	for _, t := range trees {
+		for key := range TreeSplits(t, index, rooted) {
+			counts[key]++
+		}
+	}
+	freq := make(map[string]float64, len(counts))
+	for key, count := range counts {
+		freq[key] = float64(count) / float64(len(trees))
+	}
+	return freq
+}
+
+// Consensus takes trees on the same taxa, a consensus method, the majority threshold and whether the trees are
+// rooted, and returns the consensus tree with the taxa in the order of the first tree.
+func Consensus(trees []*tree.Tree, method string, threshold float64, rooted bool) (*tree.Tree, error) {
+	if len(trees) == 0 {
+		return nil, fmt.Errorf("no trees")
+	}
+	if method != Strict && method != Majority && method != Greedy {
+		return nil, fmt.Errorf("unknown consensus method %q", method)
+	}
+	if threshold < 0.5 || threshold > 1 {
+		return nil, fmt.Errorf("majority threshold %v is not between 0.5 and 1", threshold)
+	}
+	taxa := Taxa(trees[0])
+	if err := CheckTaxa(trees, taxa); err != nil {
+		return nil, err
+	}
+	freq := SplitFrequencies(trees, taxa, rooted)
+
+	//splits from the most to the least frequent, ties in a fixed order
+	splits := make([]string, 0, len(freq))
+	for key := range freq {
+		splits = append(splits, key)
+	}
+	sort.Slice(splits, func(i, j int) bool {
+		if freq[splits[i]] != freq[splits[j]] {
+			return freq[splits[i]] > freq[splits[j]]
+		}
+		return splits[i] > splits[j]
+	})
+
+	kept := make([]string, 0)
+	for _, key := range splits {
+		switch method {
+		case Strict:
+			if freq[key] == 1 {
+				kept = append(kept, key)
+			}
+		case Majority:
+			if freq[key] > threshold || freq[key] == 1 {
+				kept = append(kept, key)
+			}
+		case Greedy:
+			compatible := true
+			for _, other := range kept {
+				if !Compatible(key, other) {
+					compatible = false
+					break
+				}
+			}
+			if compatible {
+				kept = append(kept, key)
+			}
+		}
+	}
+	return BuildTree(taxa, kept, rooted, func(key string) string {
+		return strconv.FormatFloat(100*freq[key], 'f', 0, 64)
+	}), nil
+}
@@ -0,0 +1,103 @@
+package splits
+
+import (
+	"testing"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// sameTopology fails the test unless two trees have the same splits.
+func sameTopology(t *testing.T, name string, got *tree.Tree, want string, rooted bool) {
+	wantTree := parse(t, want)
+	if RobinsonFoulds(got, wantTree, Taxa(wantTree), rooted) != 0 {
+		t.Errorf("%s consensus is %s, want %s", name, got.Newick(), want)
+	}
+}
+
+func TestConsensus(t *testing.T) {
+	// AB|CDE and DE|ABC are in two of the three trees, CE|ABD and AC|BDE in one
+	trees := []*tree.Tree{
+		parse(t, "((A,B),C,(D,E));"),
+		parse(t, "((A,B),D,(C,E));"),
+		parse(t, "((A,C),B,(D,E));"),
+	}
+	tests := []struct {
+		method    string
+		threshold float64
+		want      string
+	}{
+		{Strict, 0.5, "(A,B,C,D,E);"},
+		{Majority, 0.5, "((A,B),C,(D,E));"},
+		// a split must be in more than the threshold fraction of the trees
+		{Majority, 2.0 / 3, "(A,B,C,D,E);"},
+		{Majority, 0.6, "((A,B),C,(D,E));"},
+		{Majority, 1, "(A,B,C,D,E);"},
+		// CE|ABD and AC|BDE each conflict with one of the majority splits
+		{Greedy, 0.5, "((A,B),C,(D,E));"},
+	}
+	for _, test := range tests {
+		got, err := Consensus(trees, test.method, test.threshold, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameTopology(t, test.method, got, test.want, false)
+	}
+
+	// every node of the majority consensus is labelled with the percentage of trees that have its split
+	got, err := Consensus(trees, Majority, 0.5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range got.PreOrder() {
+		if !n.IsLeaf() && n != got.Root && n.Label != "67" {
+			t.Errorf("split support %q, want 67", n.Label)
+		}
+	}
+
+	// a split in every tree is kept at threshold 1, and by the strict consensus
+	same := []*tree.Tree{parse(t, "((A,B),C,(D,E));"), parse(t, "((B,A),(E,D),C);")}
+	for _, method := range []string{Strict, Majority} {
+		got, err := Consensus(same, method, 1, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameTopology(t, method, got, "((A,B),C,(D,E));", false)
+	}
+
+	if _, err := Consensus(trees, Majority, 0.4, false); err == nil {
+		t.Error("Consensus accepted a threshold below one half")
+	}
+	if _, err := Consensus(trees, "adams", 0.5, false); err == nil {
+		t.Error("Consensus accepted an unknown method")
+	}
+	// three taxa have no non-trivial split, so the method is checked before looking at the splits
+	if _, err := Consensus([]*tree.Tree{parse(t, "(A,B,C);")}, "bogus", 0.5, false); err == nil {
+		t.Error("Consensus accepted an unknown method for trees without splits")
+	}
+}
+
+func TestGreedyConsensusTies(t *testing.T) {
+	// AB|CD and AC|BD are each in half of the trees and conflict; ties are taken in decreasing order of split key,
+	// over the taxa in the order of the first tree. With the taxa A, B, C, D and A on side '0', the key of AC|BD
+	// (0101) comes before that of AB|CD (0011)
+	trees := []*tree.Tree{parse(t, "((A,B),(C,D));"), parse(t, "((A,C),(B,D));")}
+	got, err := Consensus(trees, Greedy, 0.5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameTopology(t, Greedy, got, "((A,C),B,D);", false)
+	// with the taxa A, C, B, D, AB|CD is written 0101 and comes first
+	got, err = Consensus([]*tree.Tree{trees[1], trees[0]}, Greedy, 0.5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameTopology(t, Greedy, got, "((A,B),C,D);", false)
+
+	// rooted, the clades AB and CD are in one tree and AC and BD in the other; AB, AC, BD, CD in key order
+	// (1100, 1010, 0101, 0011) keeps AB, then CD, which is compatible with it
+	got, err = Consensus(trees, Greedy, 0.5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameTopology(t, Greedy+" rooted", got, "((A,B),(C,D));", true)
+}
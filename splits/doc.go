@@ -0,0 +1,3 @@
+// Package splits works on collections of trees over the same taxa through the splits (or, in rooted trees, the
+// clades) of their branches: consensus trees and distances between trees.
+package splits
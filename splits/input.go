@@ -0,0 +1,101 @@
+package splits
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Trees are read with the tree package. Every tree given to a command must have labelled, unique leaves.
+
+// ReadTreesFromFiles reads every tree of every file, in order.
+func ReadTreesFromFiles(fileNames []string) ([]*tree.Tree, error) {
+	trees := make([]*tree.Tree, 0)
+	for _, fileName := range fileNames {
+		ts, err := tree.ReadAllFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		if len(ts) == 0 {
+			return nil, fmt.Errorf("%s: no trees", fileName)
+		}
+		for i, t := range ts {
+			if err := t.CheckLeaves(); err != nil {
+				return nil, fmt.Errorf("%s: tree %d: %w", fileName, i+1, err)
+			}
+		}
+		trees = append(trees, ts...)
+	}
+	return trees, nil
+}
+
+// ReadTaxonMapFromFile reads a file with one "label<TAB>taxon" line per leaf label to rename, such as the gene
+// name of a sequence and its species.
+func ReadTaxonMapFromFile(fileName string) (map[string]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	taxon := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("wrong format of taxon line %q", line)
+		}
+		taxon[fields[0]] = strings.TrimSpace(fields[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return taxon, nil
+}
+
+// RenameLeaves gives the leaves of the trees the taxon their label maps to; labels not in the map are kept.
+func RenameLeaves(trees []*tree.Tree, taxon map[string]string) error {
+	for i, t := range trees {
+		for _, leaf := range t.Leaves() {
+			if name, ok := taxon[leaf.Label]; ok {
+				leaf.Label = name
+			}
+		}
+		if err := t.CheckLeaves(); err != nil {
+			return fmt.Errorf("tree %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ReadNamedTrees reads every tree of every file and names it after its file, adding the tree number when a file
+// has several trees.
+func ReadNamedTrees(fileNames []string) ([]*tree.Tree, []string, error) {
+	trees := make([]*tree.Tree, 0)
+	names := make([]string, 0)
+	for _, fileName := range fileNames {
+		ts, err := ReadTreesFromFiles([]string{fileName})
+		if err != nil {
+			return nil, nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+		for i, t := range ts {
+			trees = append(trees, t)
+			if len(ts) == 1 {
+				names = append(names, name)
+			} else {
+				names = append(names, name+"_"+strconv.Itoa(i+1))
+			}
+		}
+	}
+	return trees, names, nil
+}
@@ -0,0 +1,176 @@
+package splits
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zhaoyuanqi/PhylogeneticsAlg/tree"
+)
+
+// Splits
+// Every branch of a tree divides its taxa into two groups. A split is written as one '0' or '1' per taxon, in the
+// order of a taxa list, with '1' for the taxa below the branch. In an unrooted tree the two sides of a branch are
+// interchangeable, so the split is flipped to put the first taxon on side '0'; in a rooted tree the '1' side is
+// the clade below the branch. Splits that separate a single taxon (or, unrooted, all taxa but one) are trivial:
+// every tree on the taxa has them.
+
+// Taxa returns the leaf labels of a tree in the order they appear in it.
+func Taxa(t *tree.Tree) []string {
+	taxa := make([]string, len(t.Leaves()))
+	for i, leaf := range t.Leaves() {
+		taxa[i] = leaf.Label
+	}
+	return taxa
+}
+
+// TaxaIndex maps each taxon to its position in taxa.
+func TaxaIndex(taxa []string) map[string]int {
+	index := make(map[string]int, len(taxa))
+	for i, taxon := range taxa {
+		index[taxon] = i
+	}
+	return index
+}
+
+// CheckTaxa returns an error unless every tree has exactly the given taxa.
+func CheckTaxa(trees []*tree.Tree, taxa []string) error {
+	index := TaxaIndex(taxa)
+	for i, t := range trees {
+		if len(t.Leaves()) != len(taxa) {
+			return fmt.Errorf("tree %d has %d taxa, tree 1 has %d", i+1, len(t.Leaves()), len(taxa))
+		}
+		for _, leaf := range t.Leaves() {
+			if _, ok := index[leaf.Label]; !ok {
+				return fmt.Errorf("taxon %q of tree %d is not in tree 1", leaf.Label, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// TreeSplits returns the non-trivial splits of a tree, each with the node below its branch.
+// Two branches with the same split (the two branches at the root of an unrooted tree) give one split.
+func TreeSplits(t *tree.Tree, index map[string]int, rooted bool) map[string]*tree.Node {
+	splits := make(map[string]*tree.Node)
+	WalkBranches(t.Root, t.Root, index, rooted, func(key string, n *tree.Node) {
+		if _, ok := splits[key]; !ok && !Trivial(key, rooted) {
+			splits[key] = n
+		}
+	})
+	return splits
+}
+
+// BranchLengths returns the total branch length of every split of a tree, trivial ones included.
+// Branches without a length count as 0.
+func BranchLengths(t *tree.Tree, index map[string]int, rooted bool) map[string]float64 {
+	lengths := make(map[string]float64)
+	WalkBranches(t.Root, t.Root, index, rooted, func(key string, n *tree.Node) {
+		lengths[key] += n.Length
+	})
+	return lengths
+}
+
+// WalkBranches returns the taxa below n and calls visit with the split and lower node of every branch below n.
+func WalkBranches(n, root *tree.Node, index map[string]int, rooted bool, visit func(key string, n *tree.Node)) []bool {
+	below := make([]bool, len(index))
+	if len(n.Children) == 0 {
+		below[index[n.Label]] = true
+	}
+	for _, child := range n.Children {
+		for k, in := range WalkBranches(child, root, index, rooted, visit) {
+			below[k] = below[k] || in
+		}
+	}
+	if n != root {
+		visit(SplitKey(below, rooted), n)
+	}
+	return below
+}
+
+// SplitKey writes the taxa below a branch as a split.
+func SplitKey(below []bool, rooted bool) string {
+	key := make([]byte, len(below))
+	flip := !rooted && below[0]
+	for k, in := range below {
+		if in != flip {
+			key[k] = '1'
+		} else {
+			key[k] = '0'
+		}
+	}
+	return string(key)
+}
+
+// Trivial returns true if a split is shared by every tree on its taxa.
+func Trivial(key string, rooted bool) bool {
+	size := strings.Count(key, "1")
+	if rooted {
+		return size < 2 || size == len(key)
+	}
+	return size < 2 || size > len(key)-2
+}
+
+// Compatible returns true if two splits can be branches of the same tree: their '1' sides are disjoint or one
+// contains the other. With the first taxon always on side '0', this is also the test for unrooted splits.
+func Compatible(a, b string) bool {
+	aInB, bInA, disjoint := true, true, true
+	for k := 0; k < len(a); k++ {
+		if a[k] == '1' && b[k] == '1' {
+			disjoint = false
+		}
+		if a[k] == '1' && b[k] == '0' {
+			aInB = false
+		}
+		if b[k] == '1' && a[k] == '0' {
+			bInA = false
+		}
+	}
+	return disjoint || aInB || bInA
+}
+
+// BuildTree takes taxa and a set of compatible splits, and returns the rooted or unrooted tree that has exactly
+// these splits.
+// label gives the label of the node below the branch of each split.
+func BuildTree(taxa []string, splits []string, rooted bool, label func(split string) string) *tree.Tree {
+	root := &tree.Node{}
+	//larger splits first, so the parent of each split is placed before it
+	sorted := append([]string(nil), splits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.Count(sorted[i], "1") > strings.Count(sorted[j], "1")
+	})
+	nodes := make([]*tree.Node, len(sorted))
+	for i, key := range sorted {
+		//the parent is the last placed split that contains this one, which is the smallest such split
+		parent := root
+		for j := i - 1; j >= 0; j-- {
+			if Contains(sorted[j], key) {
+				parent = nodes[j]
+				break
+			}
+		}
+		nodes[i] = tree.NewNode(label(key))
+		parent.AddChild(nodes[i])
+	}
+	for k, taxon := range taxa {
+		parent := root
+		for j := len(sorted) - 1; j >= 0; j-- {
+			if sorted[j][k] == '1' {
+				parent = nodes[j]
+				break
+			}
+		}
+		parent.AddChild(tree.NewNode(taxon))
+	}
+	return tree.New(root, rooted)
+}
+
+// Contains returns true if every taxon on the '1' side of b is on the '1' side of a.
+func Contains(a, b string) bool {
+	for k := 0; k < len(b); k++ {
+		if b[k] == '1' && a[k] != '1' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package tree
+
+import "fmt"
+
+// Conversions
+// Packages that keep trees in their own structures convert through a list of labelled branches, which is how
+// the nj package stores its tree, or through LeavesFirst, which is the node order of the rooted binary trees of the
+// reconcile and parsimony packages. Each of them has a ToTree method to convert its trees back, e.g. to compare them
+// or build their consensus with the splits package.
+
+// Edge is a branch between the nodes labelled From and To.
+type Edge struct {
+	From, To string
+	Length   float64
+}
+
+// Edges returns the branches of the tree in preorder, each from a parent to a child.
+func (t *Tree) Edges() []Edge {
+	edges := make([]Edge, 0)
+	t.Walk(func(n *Node) bool {
+		if n.Parent != nil {
+			edges = append(edges, Edge{From: n.Parent.Label, To: n.Label, Length: n.Length})
+		}
+		return true
+	})
+	return edges
+}
+
+// FromEdges builds the unrooted tree of a list of branches between uniquely labelled nodes, held by the node
+// labelled root. The children of a node are in the order of its branches in the list.
+func FromEdges(edges []Edge, root string) (*Tree, error) {
+	nodes := make(map[string]*Node)
+	neighbors := make(map[string][]Edge)
+	for _, e := range edges {
+		if e.From == e.To {
+			return nil, fmt.Errorf("tree: branch from %q to itself", e.From)
+		}
+		for _, label := range []string{e.From, e.To} {
+			if nodes[label] == nil {
+				nodes[label] = NewNode(label)
+			}
+		}
+		neighbors[e.From] = append(neighbors[e.From], e)
+		neighbors[e.To] = append(neighbors[e.To], Edge{From: e.To, To: e.From, Length: e.Length})
+	}
+	if nodes[root] == nil {
+		if len(edges) > 0 {
+			return nil, fmt.Errorf("tree: no node %q", root)
+		}
+		nodes[root] = NewNode(root)
+	}
+	placed := map[string]bool{root: true}
+	queue := []string{root}
+	for i := 0; i < len(queue); i++ {
+		for _, e := range neighbors[queue[i]] {
+			if placed[e.To] {
+				if nodes[e.To] != nodes[queue[i]].Parent {
+					return nil, fmt.Errorf("tree: the branches have a cycle through %q", e.To)
+				}
+				continue
+			}
+			child := nodes[e.To]
+			child.SetLength(e.Length)
+			nodes[queue[i]].AddChild(child)
+			placed[e.To] = true
+			queue = append(queue, e.To)
+		}
+	}
+	if len(placed) != len(nodes) {
+		return nil, fmt.Errorf("tree: the branches do not connect all %d nodes", len(nodes))
+	}
+	return &Tree{Root: nodes[root]}, nil
+}
+
+// NameInternalNodes gives the internal nodes without a label the labels prefix1, prefix2, ... in postorder,
+// skipping labels already in the tree.
+func (t *Tree) NameInternalNodes(prefix string) {
+	used := make(map[string]bool)
+	for _, n := range t.PreOrder() {
+		used[n.Label] = true
+	}
+	k := 1
+	for _, n := range t.PostOrder() {
+		for !n.IsLeaf() && n.Label == "" {
+			label := fmt.Sprintf("%s%d", prefix, k)
+			k++
+			if !used[label] {
+				n.Label = label
+				used[label] = true
+			}
+		}
+	}
+}
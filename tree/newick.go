@@ -0,0 +1,304 @@
+package tree
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Newick input and output
+// A string can hold several trees, each ending with ';'. Comments in square brackets are skipped, except:
+//   [&R] or [&U] before a tree, which say whether it is rooted; without one, a tree whose root has two children is
+//   taken as rooted
+//   [&key=value,...] and [&&NHX:key=value:...] after a node, which become annotations of the node, with string values
+// Annotations are written back in the first form, in key order.
+
+// Parse reads the first tree of a Newick string.
+func Parse(s string) (*Tree, error) {
+	p := parser{s: s}
+	t, err := p.parseTree()
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("newick: no tree")
+	}
+	return t, nil
+}
+
+// ParseAll reads all trees of a Newick string.
+func ParseAll(s string) ([]*Tree, error) {
+	p := parser{s: s}
+	trees := make([]*Tree, 0)
+	for {
+		t, err := p.parseTree()
+		if err != nil {
+			return nil, fmt.Errorf("tree %d: %w", len(trees)+1, err)
+		}
+		if t == nil {
+			return trees, nil
+		}
+		trees = append(trees, t)
+	}
+}
+
+// ReadFile reads the first tree of a Newick file.
+func ReadFile(fileName string) (*Tree, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	t, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return t, nil
+}
+
+// ReadAllFile reads all trees of a Newick file.
+func ReadAllFile(fileName string) ([]*Tree, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	trees, err := ParseAll(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return trees, nil
+}
+
+// WriteFile writes the trees to a file in Newick format, one per line.
+func WriteFile(fileName string, trees ...*Tree) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	for _, t := range trees {
+		if _, err := fmt.Fprintln(file, t.Newick()); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// Newick returns the tree in Newick format.
+func (t *Tree) Newick() string {
+	var sb strings.Builder
+	if t.Root != nil {
+		writeNode(&sb, t.Root)
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+func writeNode(sb *strings.Builder, n *Node) {
+	if len(n.Children) > 0 {
+		sb.WriteString("(")
+		for i, child := range n.Children {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			writeNode(sb, child)
+		}
+		sb.WriteString(")")
+	}
+	sb.WriteString(QuoteLabel(n.Label))
+	if n.HasLength && n.Parent != nil {
+		sb.WriteString(":")
+		sb.WriteString(strconv.FormatFloat(n.Length, 'g', -1, 64))
+	}
+	if len(n.Annotations) > 0 {
+		keys := make([]string, 0, len(n.Annotations))
+		for key := range n.Annotations {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		//values can't contain the punctuation of the comment
+		clean := strings.NewReplacer("[", "", "]", "", ",", "", "=", "")
+		sb.WriteString("[&")
+		for i, key := range keys {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(clean.Replace(key) + "=" + clean.Replace(fmt.Sprint(n.Annotations[key])))
+		}
+		sb.WriteString("]")
+	}
+}
+
+// QuoteLabel returns the label as it should be written in a Newick string.
+// Labels containing Newick punctuation or whitespace are put in single quotes.
+func QuoteLabel(label string) string {
+	if label == "" || !strings.ContainsAny(label, "()[]':;, \t\r\n") {
+		return label
+	}
+	return "'" + strings.ReplaceAll(label, "'", "''") + "'"
+}
+
+// parser is a recursive descent parser over a Newick string. comments holds the comments skipped since the last
+// node was finished.
+type parser struct {
+	s        string
+	pos      int
+	comments []string
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("newick: position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// parseTree parses the next tree, or returns nil at the end of the string.
+func (p *parser) parseTree() (*Tree, error) {
+	p.comments = nil
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, nil
+	}
+	rooted, given := false, false
+	for _, c := range p.comments {
+		switch strings.ToUpper(c) {
+		case "&R":
+			rooted, given = true, true
+		case "&U":
+			rooted, given = false, true
+		}
+	}
+	p.comments = nil
+	root, err := p.parseNode()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos >= len(p.s) || p.s[p.pos] != ';' {
+		return nil, p.errorf("expected ';' at the end of the tree")
+	}
+	p.pos++
+	p.annotate(root)
+	if !given {
+		rooted = len(root.Children) == 2
+	}
+	return &Tree{Root: root, Rooted: rooted}, nil
+}
+
+// skipSpace moves past whitespace and comments.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '[':
+			end := strings.IndexByte(p.s[p.pos:], ']')
+			if end < 0 {
+				p.pos = len(p.s)
+				return
+			}
+			p.comments = append(p.comments, p.s[p.pos+1:p.pos+end])
+			p.pos += end + 1
+		default:
+			return
+		}
+	}
+}
+
+// annotate adds the annotations in the skipped comments to n.
+func (p *parser) annotate(n *Node) {
+	for _, c := range p.comments {
+		var fields []string
+		switch {
+		case strings.HasPrefix(c, "&&NHX:"):
+			fields = strings.Split(c[len("&&NHX:"):], ":")
+		case strings.HasPrefix(c, "&"):
+			fields = strings.Split(c[1:], ",")
+		}
+		for _, field := range fields {
+			if kv := strings.SplitN(field, "=", 2); len(kv) == 2 {
+				n.Annotate(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+			}
+		}
+	}
+	p.comments = nil
+}
+
+// parseNode parses a subtree: an optional list of children, a label, a branch length and annotations.
+func (p *parser) parseNode() (*Node, error) {
+	n := &Node{}
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		for {
+			child, err := p.parseNode()
+			if err != nil {
+				return nil, err
+			}
+			n.AddChild(child)
+			p.skipSpace()
+			p.annotate(child)
+			if p.pos >= len(p.s) {
+				return nil, p.errorf("unexpected end of tree")
+			}
+			if p.s[p.pos] == ',' {
+				p.pos++
+				continue
+			}
+			if p.s[p.pos] == ')' {
+				p.pos++
+				break
+			}
+			return nil, p.errorf("unexpected character %q", p.s[p.pos])
+		}
+	}
+	label, err := p.parseLabel()
+	if err != nil {
+		return nil, err
+	}
+	n.Label = label
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == ':' {
+		p.pos++
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("0123456789+-.eE", p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		length, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("bad branch length %q", p.s[start:p.pos])
+		}
+		n.SetLength(length)
+	}
+	return n, nil
+}
+
+// parseLabel parses a quoted or unquoted label, which may be empty.
+func (p *parser) parseLabel() (string, error) {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '\'' {
+		var sb strings.Builder
+		p.pos++
+		for {
+			if p.pos >= len(p.s) {
+				return "", p.errorf("unterminated quoted label")
+			}
+			if p.s[p.pos] == '\'' {
+				if p.pos+1 < len(p.s) && p.s[p.pos+1] == '\'' {
+					sb.WriteByte('\'')
+					p.pos += 2
+					continue
+				}
+				p.pos++
+				return sb.String(), nil
+			}
+			sb.WriteByte(p.s[p.pos])
+			p.pos++
+		}
+	}
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("()[]':;, \t\r\n", p.s[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
@@ -0,0 +1,92 @@
+package tree
+
+import "errors"
+
+// Rooting
+// Rerooting turns the branches on the path from the new root to the old one around, so every node keeps its
+// neighbors and branch lengths. An old root left with a single child is removed, its two branches joined into one.
+
+// Reroot makes n the root of the tree without adding a node.
+func (t *Tree) Reroot(n *Node) {
+	if n == t.Root {
+		return
+	}
+	old := t.Root
+	path := make([]*Node, 0)
+	for x := n; x != nil; x = x.Parent {
+		path = append(path, x)
+	}
+	//from the top down, the parent becomes a child and takes over the length of the branch between them
+	for i := len(path) - 1; i > 0; i-- {
+		parent, child := path[i], path[i-1]
+		parent.RemoveChild(child)
+		child.Children = append(child.Children, parent)
+		parent.Parent = child
+		parent.Length, parent.HasLength = child.Length, child.HasLength
+	}
+	n.Parent = nil
+	n.Length, n.HasLength = 0, false
+	t.Root = n
+	if len(old.Children) == 1 {
+		Suppress(old)
+	}
+}
+
+// RootAbove roots the tree on the branch above n, at the given fraction of the branch length from n.
+// The new root is a node without a label.
+func (t *Tree) RootAbove(n *Node, fraction float64) error {
+	if n.Parent == nil {
+		return errors.New("tree: the root has no branch above it")
+	}
+	if fraction < 0 || fraction > 1 {
+		return errors.New("tree: the root position is not a fraction of the branch")
+	}
+	parent := n.Parent
+	root := &Node{}
+	root.Length, root.HasLength = (1-fraction)*n.Length, n.HasLength
+	n.Length = fraction * n.Length
+	for i, c := range parent.Children {
+		if c == n {
+			parent.Children[i] = root
+		}
+	}
+	root.Parent = parent
+	root.AddChild(n)
+	t.Reroot(root)
+	t.Rooted = true
+	return nil
+}
+
+// Unroot removes a root with two children, joining its two branches, so the tree is held by an internal node
+// with at least three neighbors.
+func (t *Tree) Unroot() {
+	t.Rooted = false
+	root := t.Root
+	if len(root.Children) != 2 {
+		return
+	}
+	for _, child := range root.Children {
+		if !child.IsLeaf() {
+			t.Reroot(child)
+			return
+		}
+	}
+}
+
+// Suppress removes a node with a parent and a single child, joining its two branches into one.
+func Suppress(n *Node) {
+	if n.Parent == nil || len(n.Children) != 1 {
+		return
+	}
+	parent, child := n.Parent, n.Children[0]
+	child.Length += n.Length
+	child.HasLength = child.HasLength || n.HasLength
+	child.Parent = parent
+	for i, c := range parent.Children {
+		if c == n {
+			parent.Children[i] = child
+		}
+	}
+	n.Parent = nil
+	n.Children = nil
+}
@@ -0,0 +1,225 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Rooting methods
+// Each method picks a branch of the tree, rooted or not, and a position on it, and roots the tree there with
+// RootAbove. The branch above a node n is the branch between n and its parent; the two branches below the root of
+// a rooted tree are one branch of the unrooted tree, so choosing either of them gives the same rooting.
+
+// RootOutgroup roots the tree in the middle of the branch that separates the leaves with the given labels from the
+// other leaves. It returns an error if a label is not a leaf or the outgroup leaves are not on one side of a branch.
+func (t *Tree) RootOutgroup(labels ...string) error {
+	outgroup := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		leaf := t.Find(label)
+		if leaf == nil || !leaf.IsLeaf() {
+			return fmt.Errorf("tree: no leaf %q to root with", label)
+		}
+		outgroup[label] = true
+	}
+	leaves := len(t.Leaves())
+	if len(outgroup) == 0 || len(outgroup) == leaves {
+		return errors.New("tree: the outgroup must have at least one leaf and leave out at least one")
+	}
+	//count the leaves and the outgroup leaves below each node; the branch above a node is the outgroup branch
+	//if the outgroup is what is below it, or everything but what is below it
+	below := make(map[*Node]int)
+	inOutgroup := make(map[*Node]int)
+	for _, n := range t.PostOrder() {
+		if n.IsLeaf() {
+			below[n] = 1
+			if outgroup[n.Label] {
+				inOutgroup[n] = 1
+			}
+		}
+		for _, c := range n.Children {
+			below[n] += below[c]
+			inOutgroup[n] += inOutgroup[c]
+		}
+		if n.Parent == nil {
+			continue
+		}
+		if inOutgroup[n] == len(outgroup) && below[n] == len(outgroup) ||
+			inOutgroup[n] == 0 && below[n] == leaves-len(outgroup) {
+			return t.RootAbove(n, 0.5)
+		}
+	}
+	return errors.New("tree: the outgroup leaves are not separated from the other leaves by a branch")
+}
+
+// RootMidpoint roots the tree in the middle of the longest path between two leaves, so both leaves are at the
+// same distance from the root.
+func (t *Tree) RootMidpoint() error {
+	leaves := t.Leaves()
+	if len(leaves) < 2 {
+		return errors.New("tree: midpoint rooting needs at least two leaves")
+	}
+	//the leaf farthest from any leaf is an end of a longest path, and the leaf farthest from it the other end
+	a, _ := farthestLeaf(leaves[0])
+	b, length := farthestLeaf(a)
+	path := Path(a, b)
+	half := length / 2
+	travelled := 0.0
+	for i := 0; i+1 < len(path); i++ {
+		n, next := path[i], path[i+1]
+		lower := n
+		if next.Parent == n {
+			lower = next
+		}
+		if travelled+lower.Length < half && i+2 < len(path) {
+			travelled += lower.Length
+			continue
+		}
+		//the midpoint is at half-travelled from n on this branch
+		fraction := 0.0
+		if lower.Length > 0 {
+			fraction = (half - travelled) / lower.Length
+		}
+		fraction = math.Max(0, math.Min(1, fraction))
+		if lower != n {
+			fraction = 1 - fraction
+		}
+		return t.RootAbove(lower, fraction)
+	}
+	return errors.New("tree: no path between the leaves")
+}
+
+// farthestLeaf returns the leaf farthest from start, by the sum of the branch lengths, and its distance.
+func farthestLeaf(start *Node) (*Node, float64) {
+	dist := map[*Node]float64{start: 0}
+	farthest, max := start, 0.0
+	WalkFrom(start, func(n, from *Node) bool {
+		if from == nil {
+			return true
+		}
+		if n.Parent == from {
+			dist[n] = dist[from] + n.Length
+		} else {
+			dist[n] = dist[from] + from.Length
+		}
+		if n.IsLeaf() && dist[n] > max {
+			farthest, max = n, dist[n]
+		}
+		return true
+	})
+	return farthest, max
+}
+
+// RootMinVariance roots the tree where the variance of the distances from the root to the leaves is the lowest
+// (Mai, Sayyari and Mirarab 2017).
+func (t *Tree) RootMinVariance() error {
+	leaves := len(t.Leaves())
+	if leaves < 2 {
+		return errors.New("tree: minimum variance rooting needs at least two leaves")
+	}
+	//below[n] sums the distances from n to the leaves below it, above[n] those from the parent of n to the
+	//leaves that are not below n
+	below := make(map[*Node]distanceSums)
+	for _, n := range t.PostOrder() {
+		if n.IsLeaf() {
+			below[n] = distanceSums{count: 1}
+		}
+		for _, c := range n.Children {
+			below[n] = below[n].add(below[c].shift(c.Length))
+		}
+	}
+	above := make(map[*Node]distanceSums)
+	var best *Node
+	bestX, bestVariance := 0.0, math.Inf(1)
+	for _, n := range t.PreOrder() {
+		if n.Parent == nil {
+			continue
+		}
+		p := n.Parent
+		if p.Parent != nil {
+			above[n] = above[p].shift(p.Length)
+		}
+		for _, s := range p.Children {
+			if s != n {
+				above[n] = above[n].add(below[s].shift(s.Length))
+			}
+		}
+		x, variance := minVarianceOnBranch(below[n], above[n], n.Length)
+		if variance < bestVariance {
+			best, bestX, bestVariance = n, x, variance
+		}
+	}
+	if best == nil {
+		return errors.New("tree: no branch to root on")
+	}
+	fraction := 0.0
+	if best.Length > 0 {
+		fraction = bestX / best.Length
+	}
+	return t.RootAbove(best, fraction)
+}
+
+// distanceSums holds the number of leaves, the sum of their distances and the sum of the squares of their distances
+// from a point of the tree.
+type distanceSums struct {
+	count   int
+	sum, sq float64
+}
+
+func (d distanceSums) add(e distanceSums) distanceSums {
+	return distanceSums{d.count + e.count, d.sum + e.sum, d.sq + e.sq}
+}
+
+// shift returns the sums when every distance is longer by length.
+func (d distanceSums) shift(length float64) distanceSums {
+	n := float64(d.count)
+	return distanceSums{d.count, d.sum + n*length, d.sq + 2*length*d.sum + n*length*length}
+}
+
+// minVarianceOnBranch returns the distance x from the lower end of a branch where the variance of the root to leaf
+// distances is the lowest, and that variance, given the sums of the distances from the lower end to the leaves
+// below it and from the upper end to the other leaves.
+func minVarianceOnBranch(lower, upper distanceSums, length float64) (float64, float64) {
+	variance := func(x float64) float64 {
+		d := lower.shift(x).add(upper.shift(length - x))
+		n := float64(d.count)
+		mean := d.sum / n
+		return d.sq/n - mean*mean
+	}
+	//the variance is a parabola in x, so its minimum is found from three of its values
+	if length <= 0 {
+		return 0, variance(0)
+	}
+	v0, v1, v2 := variance(0), variance(length/2), variance(length)
+	//the slope of the parabola at length/2 is slope and its second derivative curve, so its vertex is at
+	//length/2 - slope/curve
+	curve := 4 * (v0 - 2*v1 + v2) / (length * length)
+	slope := (v2 - v0) / length
+	x := 0.0
+	if curve > 0 {
+		x = math.Max(0, math.Min(length, length/2-slope/curve))
+	} else if v2 < v0 {
+		x = length
+	}
+	return x, variance(x)
+}
+
+// Rootings returns a copy of the tree rooted in the middle of each branch of its unrooted view, in preorder.
+// It returns an error if RootAbove does.
+func (t *Tree) Rootings() ([]*Tree, error) {
+	u := t.Copy()
+	u.Unroot()
+	rootings := make([]*Tree, 0)
+	for i, n := range u.PreOrder() {
+		if n.Parent == nil {
+			continue
+		}
+		c := u.Copy()
+		//the copy has the same preorder, so its i-th node is n
+		if err := c.RootAbove(c.PreOrder()[i], 0.5); err != nil {
+			return nil, err
+		}
+		rootings = append(rootings, c)
+	}
+	return rootings, nil
+}
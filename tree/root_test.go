@@ -0,0 +1,202 @@
+package tree
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// example is an unrooted binary tree with five leaves; its longest path is from B to E, of length 18.
+const example = "((A:1,B:2):3,C:4,(D:5,E:6):7);"
+
+// parse returns the tree of a Newick string.
+func parse(t *testing.T, s string) *Tree {
+	tr, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+// rootDistances returns the distance from the root to each leaf by label.
+func rootDistances(tr *Tree) map[string]float64 {
+	dist := make(map[string]float64)
+	for _, leaf := range tr.Leaves() {
+		dist[leaf.Label] = PathLength(tr.Root, leaf)
+	}
+	return dist
+}
+
+// rootVariance returns the variance of the distances from the root to the leaves.
+func rootVariance(tr *Tree) float64 {
+	sum, sq := 0.0, 0.0
+	dist := rootDistances(tr)
+	for _, d := range dist {
+		sum += d
+		sq += d * d
+	}
+	n := float64(len(dist))
+	return sq/n - (sum/n)*(sum/n)
+}
+
+// rootSplit returns the leaves on each side of the root of a rooted binary tree, the side with the first leaf in
+// alphabetical order first.
+func rootSplit(tr *Tree) string {
+	sides := make([]string, 2)
+	for i, child := range tr.Root.Children {
+		labels := New(child, false).LeafLabels()
+		sort.Strings(labels)
+		sides[i] = strings.Join(labels, "")
+	}
+	sort.Strings(sides)
+	return sides[0] + "|" + sides[1]
+}
+
+// childLengths returns the branch lengths of the children of the root, shortest first.
+func childLengths(tr *Tree) []float64 {
+	lengths := make([]float64, 0)
+	for _, child := range tr.Root.Children {
+		lengths = append(lengths, child.Length)
+	}
+	sort.Float64s(lengths)
+	return lengths
+}
+
+func TestRootMidpoint(t *testing.T) {
+	tr := parse(t, example)
+	if err := tr.RootMidpoint(); err != nil {
+		t.Fatal(err)
+	}
+	// the midpoint is 9 from B and E, 3 up the branch of length 7 from the parent of D and E
+	if got := rootSplit(tr); got != "ABC|DE" {
+		t.Errorf("midpoint root splits %s, want ABC|DE", got)
+	}
+	if got := childLengths(tr); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Errorf("branches at the root have lengths %v, want [3 4]", got)
+	}
+	dist := rootDistances(tr)
+	if dist["B"] != 9 || dist["E"] != 9 {
+		t.Errorf("root is %v from B and %v from E, want 9", dist["B"], dist["E"])
+	}
+	if !tr.Rooted {
+		t.Error("the tree is not marked rooted")
+	}
+}
+
+func TestRootOutgroup(t *testing.T) {
+	tr := parse(t, example)
+	if err := tr.RootOutgroup("E", "D"); err != nil {
+		t.Fatal(err)
+	}
+	if got := rootSplit(tr); got != "ABC|DE" {
+		t.Errorf("outgroup root splits %s, want ABC|DE", got)
+	}
+	if got := childLengths(tr); len(got) != 2 || got[0] != 3.5 || got[1] != 3.5 {
+		t.Errorf("branches at the root have lengths %v, want [3.5 3.5]", got)
+	}
+
+	// the outgroup may be on the upper side of its branch, and a single leaf
+	tr = parse(t, "((A:1,B:2):3,(C:4,(D:5,E:6):7):1);")
+	if err := tr.RootOutgroup("A", "B", "C"); err != nil {
+		t.Fatal(err)
+	}
+	if got := rootSplit(tr); got != "ABC|DE" {
+		t.Errorf("outgroup root splits %s, want ABC|DE", got)
+	}
+	if err := tr.RootOutgroup("C"); err != nil {
+		t.Fatal(err)
+	}
+	if got := rootSplit(tr); got != "ABDE|C" {
+		t.Errorf("outgroup root splits %s, want ABDE|C", got)
+	}
+
+	for _, outgroup := range [][]string{{"A", "C"}, {"A", "D"}, {"B", "C", "E"}, {"F"}, {}, {"A", "B", "C", "D", "E"}} {
+		if err := parse(t, example).RootOutgroup(outgroup...); err == nil {
+			t.Errorf("rooted with the outgroup %v", outgroup)
+		}
+	}
+}
+
+func TestRootMinVariance(t *testing.T) {
+	// with leaves at 1, 1 and 4 from the center, the variance is lowest where 1+x = 4-x on the branch to C
+	tr := parse(t, "(A:1,B:1,C:4);")
+	if err := tr.RootMinVariance(); err != nil {
+		t.Fatal(err)
+	}
+	if got := rootSplit(tr); got != "AB|C" {
+		t.Errorf("minimum variance root splits %s, want AB|C", got)
+	}
+	if got := childLengths(tr); len(got) != 2 || got[0] != 1.5 || got[1] != 2.5 {
+		t.Errorf("branches at the root have lengths %v, want [1.5 2.5]", got)
+	}
+	if v := rootVariance(tr); v > 1e-12 {
+		t.Errorf("root to leaf variance %v, want 0", v)
+	}
+
+	// no point of any branch has a lower variance than the one found
+	tr = parse(t, example)
+	if err := tr.RootMinVariance(); err != nil {
+		t.Fatal(err)
+	}
+	best := rootVariance(tr)
+	for i := range parse(t, example).PreOrder() {
+		for _, fraction := range []float64{0, 0.1, 0.25, 0.4, 0.5, 0.6, 0.75, 0.9, 1} {
+			other := parse(t, example)
+			n := other.PreOrder()[i]
+			if n.Parent == nil {
+				continue
+			}
+			if err := other.RootAbove(n, fraction); err != nil {
+				t.Fatal(err)
+			}
+			if v := rootVariance(other); v < best-1e-9 {
+				t.Errorf("root at %v above %q has variance %v, less than the minimum %v at %s", fraction, n.Label, v, best, tr.Newick())
+			}
+		}
+	}
+}
+
+func TestMinVarianceOnBranch(t *testing.T) {
+	// two leaves at 1 below the lower end and one at 2 above the upper end of a branch of length 3: the distances
+	// 1+x, 1+x and 5-x are equal at x = 2
+	lower := distanceSums{count: 2, sum: 2, sq: 2}
+	upper := distanceSums{count: 1, sum: 2, sq: 4}
+	x, v := minVarianceOnBranch(lower, upper, 3)
+	if math.Abs(x-2) > 1e-9 || math.Abs(v) > 1e-9 {
+		t.Errorf("minVarianceOnBranch = %v, %v, want 2, 0", x, v)
+	}
+	// the vertex is past the upper end, so the minimum is at the end of the branch
+	x, _ = minVarianceOnBranch(lower, upper, 1)
+	if x != 1 {
+		t.Errorf("minVarianceOnBranch on a short branch = %v, want 1", x)
+	}
+}
+
+func TestRootings(t *testing.T) {
+	for _, s := range []string{example, "((A,B),(C,D));", "(A,B,C);", "((((A,B),C),D),(E,F));"} {
+		tr := parse(t, s)
+		n := len(tr.Leaves())
+		rootings, err := tr.Rootings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rootings) != 2*n-3 {
+			t.Errorf("%s has %d rootings, want %d", s, len(rootings), 2*n-3)
+		}
+		seen := make(map[string]bool)
+		for _, r := range rootings {
+			if !r.Rooted || len(r.Root.Children) != 2 || len(r.Leaves()) != n {
+				t.Errorf("rooting %s of %s is not a rooted binary tree on its leaves", r.Newick(), s)
+			}
+			if seen[rootSplit(r)] {
+				t.Errorf("%s is rooted twice between %s", s, rootSplit(r))
+			}
+			seen[rootSplit(r)] = true
+		}
+		// the tree itself is left as it was
+		if tr.Newick() != parse(t, s).Newick() {
+			t.Errorf("Rootings changed %s into %s", s, tr.Newick())
+		}
+	}
+}
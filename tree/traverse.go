@@ -0,0 +1,140 @@
+package tree
+
+// Traversals
+// The rooted traversals list the nodes below the root; Walk and WalkFrom call a function on each node instead and
+// can stop early. WalkFrom moves along branches without regard to the root, which is the unrooted view of the tree.
+
+// PreOrder returns the nodes of the tree, each before its children.
+func (t *Tree) PreOrder() []*Node {
+	nodes := make([]*Node, 0)
+	t.Walk(func(n *Node) bool {
+		nodes = append(nodes, n)
+		return true
+	})
+	return nodes
+}
+
+// PostOrder returns the nodes of the tree, each after its children, so the root is last.
+func (t *Tree) PostOrder() []*Node {
+	nodes := make([]*Node, 0)
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		for _, child := range n.Children {
+			visit(child)
+		}
+		nodes = append(nodes, n)
+	}
+	if t.Root != nil {
+		visit(t.Root)
+	}
+	return nodes
+}
+
+// LevelOrder returns the nodes of the tree by their number of branches from the root.
+func (t *Tree) LevelOrder() []*Node {
+	if t.Root == nil {
+		return nil
+	}
+	nodes := []*Node{t.Root}
+	for i := 0; i < len(nodes); i++ {
+		nodes = append(nodes, nodes[i].Children...)
+	}
+	return nodes
+}
+
+// LeavesFirst returns the leaves from left to right followed by the internal nodes in postorder. This is the layout
+// of the node slices of the reconcile and parsimony packages, whose root is the last node.
+func (t *Tree) LeavesFirst() []*Node {
+	leaves := make([]*Node, 0)
+	internals := make([]*Node, 0)
+	for _, n := range t.PostOrder() {
+		if n.IsLeaf() {
+			leaves = append(leaves, n)
+		} else {
+			internals = append(internals, n)
+		}
+	}
+	return append(leaves, internals...)
+}
+
+// Walk calls visit on the nodes of the tree in preorder until it returns false.
+func (t *Tree) Walk(visit func(n *Node) bool) {
+	if t.Root != nil {
+		walk(t.Root, visit)
+	}
+}
+
+func walk(n *Node, visit func(n *Node) bool) bool {
+	if !visit(n) {
+		return false
+	}
+	for _, child := range n.Children {
+		if !walk(child, visit) {
+			return false
+		}
+	}
+	return true
+}
+
+// WalkFrom calls visit on every node, starting at start and moving along branches, with the neighbor it was
+// reached from (nil for start), until visit returns false.
+func WalkFrom(start *Node, visit func(n, from *Node) bool) {
+	walkFrom(start, nil, visit)
+}
+
+func walkFrom(n, from *Node, visit func(n, from *Node) bool) bool {
+	if !visit(n, from) {
+		return false
+	}
+	for _, next := range n.Neighbors() {
+		if next != from && !walkFrom(next, n, visit) {
+			return false
+		}
+	}
+	return true
+}
+
+// Path returns the nodes on the path from a to b, both included.
+func Path(a, b *Node) []*Node {
+	ancestor := CommonAncestor(a, b)
+	up := make([]*Node, 0)
+	for n := a; n != ancestor; n = n.Parent {
+		up = append(up, n)
+	}
+	down := make([]*Node, 0)
+	for n := b; n != ancestor; n = n.Parent {
+		down = append(down, n)
+	}
+	up = append(up, ancestor)
+	for i := len(down) - 1; i >= 0; i-- {
+		up = append(up, down[i])
+	}
+	return up
+}
+
+// PathLength returns the sum of the branch lengths on the path from a to b.
+func PathLength(a, b *Node) float64 {
+	ancestor := CommonAncestor(a, b)
+	length := 0.0
+	for n := a; n != ancestor; n = n.Parent {
+		length += n.Length
+	}
+	for n := b; n != ancestor; n = n.Parent {
+		length += n.Length
+	}
+	return length
+}
+
+// CommonAncestor returns the lowest node that has both a and b below it, or nil if they are not in the same tree.
+func CommonAncestor(a, b *Node) *Node {
+	above := make(map[*Node]bool)
+	for n := a; n != nil; n = n.Parent {
+		above[n] = true
+	}
+	for n := b; n != nil; n = n.Parent {
+		if above[n] {
+			return n
+		}
+	}
+	return nil
+}
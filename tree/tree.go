@@ -0,0 +1,178 @@
+// Package tree is the phylogenetic tree shared by the packages of this repository.
+//
+// A Tree is a set of linked Nodes hanging from a root. The same structure serves as a rooted tree, where the root
+// is the common ancestor and the parent and children of a node mean what they say, and as an unrooted tree, where
+// the root is only the node the tree is held by and a node's neighbors are its parent and children. Rooted tells
+// which view is meant; Reroot, RootAbove and Unroot move between them.
+package tree
+
+import "fmt"
+
+// Node is a node of a tree. Length is the length of the branch to the parent, if HasLength is set.
+// Annotations hold any other data on the node, such as support values or reconciliation events; they are written
+// to and read from Newick comments.
+type Node struct {
+	Label       string
+	Length      float64
+	HasLength   bool
+	Parent      *Node
+	Children    []*Node
+	Annotations map[string]interface{}
+}
+
+// Tree is a tree held by its root node.
+type Tree struct {
+	Root   *Node
+	Rooted bool
+}
+
+// New returns a tree with the given root.
+func New(root *Node, rooted bool) *Tree {
+	return &Tree{Root: root, Rooted: rooted}
+}
+
+// NewNode returns a node with the given label and no branch length.
+func NewNode(label string) *Node {
+	return &Node{Label: label}
+}
+
+// AddChild appends child to the children of n.
+func (n *Node) AddChild(child *Node) {
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// RemoveChild removes child from the children of n and returns false if it was not a child of n.
+func (n *Node) RemoveChild(child *Node) bool {
+	for i, c := range n.Children {
+		if c == child {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			child.Parent = nil
+			return true
+		}
+	}
+	return false
+}
+
+// SetLength sets the length of the branch to the parent.
+func (n *Node) SetLength(length float64) {
+	n.Length = length
+	n.HasLength = true
+}
+
+// IsLeaf returns true if n has no children.
+func (n *Node) IsLeaf() bool {
+	return len(n.Children) == 0
+}
+
+// IsRoot returns true if n has no parent.
+func (n *Node) IsRoot() bool {
+	return n.Parent == nil
+}
+
+// Degree returns the number of neighbors of n in the unrooted view.
+func (n *Node) Degree() int {
+	if n.Parent == nil {
+		return len(n.Children)
+	}
+	return len(n.Children) + 1
+}
+
+// Neighbors returns the parent of n, if any, followed by its children.
+func (n *Node) Neighbors() []*Node {
+	neighbors := make([]*Node, 0, n.Degree())
+	if n.Parent != nil {
+		neighbors = append(neighbors, n.Parent)
+	}
+	return append(neighbors, n.Children...)
+}
+
+// Annotate sets an annotation of n.
+func (n *Node) Annotate(key string, value interface{}) {
+	if n.Annotations == nil {
+		n.Annotations = make(map[string]interface{})
+	}
+	n.Annotations[key] = value
+}
+
+// Annotation returns an annotation of n and whether n has it.
+func (n *Node) Annotation(key string) (interface{}, bool) {
+	value, ok := n.Annotations[key]
+	return value, ok
+}
+
+// Leaves returns the leaves of the tree from left to right.
+func (t *Tree) Leaves() []*Node {
+	leaves := make([]*Node, 0)
+	for _, n := range t.PreOrder() {
+		if n.IsLeaf() {
+			leaves = append(leaves, n)
+		}
+	}
+	return leaves
+}
+
+// LeafLabels returns the labels of the leaves of the tree from left to right.
+func (t *Tree) LeafLabels() []string {
+	leaves := t.Leaves()
+	labels := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		labels[i] = leaf.Label
+	}
+	return labels
+}
+
+// Find returns the first node in preorder with the given label, or nil.
+func (t *Tree) Find(label string) *Node {
+	for _, n := range t.PreOrder() {
+		if n.Label == label {
+			return n
+		}
+	}
+	return nil
+}
+
+// CheckLeaves returns an error unless every leaf has a label and no two leaves have the same one.
+func (t *Tree) CheckLeaves() error {
+	seen := make(map[string]bool)
+	for _, leaf := range t.Leaves() {
+		if leaf.Label == "" {
+			return fmt.Errorf("tree: leaf without a label")
+		}
+		if seen[leaf.Label] {
+			return fmt.Errorf("tree: leaf %q appears more than once", leaf.Label)
+		}
+		seen[leaf.Label] = true
+	}
+	return nil
+}
+
+// IsBinary returns true if every internal node of a rooted tree has two children, or every internal node of an
+// unrooted tree has three neighbors.
+func (t *Tree) IsBinary() bool {
+	for _, n := range t.PreOrder() {
+		if n.IsLeaf() {
+			continue
+		}
+		if t.Rooted && len(n.Children) != 2 || !t.Rooted && n.Degree() != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy returns a deep copy of the tree. Annotation maps are copied, the values in them are shared.
+func (t *Tree) Copy() *Tree {
+	return &Tree{Root: copyNode(t.Root, nil), Rooted: t.Rooted}
+}
+
+func copyNode(n, parent *Node) *Node {
+	c := &Node{Label: n.Label, Length: n.Length, HasLength: n.HasLength, Parent: parent}
+	for key, value := range n.Annotations {
+		c.Annotate(key, value)
+	}
+	for _, child := range n.Children {
+		c.Children = append(c.Children, copyNode(child, c))
+	}
+	return c
+}